@@ -0,0 +1,133 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// Audited entities.
+const (
+	AuditEntityList = "list"
+	AuditEntityItem = "item"
+)
+
+// AuditActions are the actions recorded in the audit log. They are named
+// like the events the changes publish.
+var AuditActions = []string{
+	EventListCreated, EventListUpdated, EventListDeleted, EventListArchived, EventListUnarchived, EventListRestored,
+	EventItemCreated, EventItemUpdated, EventItemDeleted, EventItemRestored,
+}
+
+// RequestInfo identifies the request a change was made in.
+type RequestInfo struct {
+	Id string
+	IP string
+}
+
+// AuditEntry records who changed a list or an item, how and when. Before
+// and After hold the fields the change touched, as they were and as they
+// became; Before is null for creations and After for deletions.
+type AuditEntry struct {
+	Id        int64           `json:"id" db:"id"`
+	ActorId   int             `json:"actor_id" db:"actor_id"`
+	Action    string          `json:"action" db:"action"`
+	Entity    string          `json:"entity" db:"entity"`
+	EntityId  int             `json:"entity_id" db:"entity_id"`
+	ListId    int             `json:"list_id" db:"list_id"`
+	Before    json.RawMessage `json:"before" db:"before" swaggertype:"object"`
+	After     json.RawMessage `json:"after" db:"after" swaggertype:"object"`
+	RequestId *string         `json:"request_id" db:"request_id"`
+	IP        *string         `json:"ip" db:"ip"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter selects audit entries; every set field has to match.
+type AuditFilter struct {
+	ActorId  *int
+	Action   string
+	Entity   string
+	EntityId *int
+	ListId   *int
+	From     *time.Time
+	To       *time.Time
+}
+
+func (f AuditFilter) Validate() error {
+	var verr ValidationError
+	if f.Action != "" && !slices.Contains(AuditActions, f.Action) {
+		verr.Add("action", "is not an audited action")
+	}
+	if f.Entity != "" && f.Entity != AuditEntityList && f.Entity != AuditEntityItem {
+		verr.Add("entity", "must be list or item")
+	}
+	if f.EntityId != nil && f.Entity == "" {
+		verr.Add("entity_id", "requires entity")
+	}
+	if f.From != nil && f.To != nil && f.To.Before(*f.From) {
+		verr.Add("to", "must not be before from")
+	}
+
+	return verr.OrNil()
+}
+
+// AuditDiff returns the JSON fields that differ between two states of an
+// entity, as they were and as they became. A nil state stands for one the
+// entity did not exist in, and all fields of the other are returned.
+func AuditDiff(before, after interface{}) (json.RawMessage, json.RawMessage, error) {
+	if before == nil || after == nil {
+		b, err := marshalState(before)
+		if err != nil {
+			return nil, nil, err
+		}
+		a, err := marshalState(after)
+		return b, a, err
+	}
+
+	var b, a map[string]json.RawMessage
+	if err := remarshal(before, &b); err != nil {
+		return nil, nil, err
+	}
+	if err := remarshal(after, &a); err != nil {
+		return nil, nil, err
+	}
+
+	changedBefore := make(map[string]json.RawMessage)
+	changedAfter := make(map[string]json.RawMessage)
+	for key, value := range b {
+		if other, ok := a[key]; !ok || !bytes.Equal(value, other) {
+			changedBefore[key] = value
+		}
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || !bytes.Equal(value, other) {
+			changedAfter[key] = value
+		}
+	}
+
+	bJSON, err := json.Marshal(changedBefore)
+	if err != nil {
+		return nil, nil, err
+	}
+	aJSON, err := json.Marshal(changedAfter)
+
+	return bJSON, aJSON, err
+}
+
+func marshalState(state interface{}) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(state)
+}
+
+func remarshal(src interface{}, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, dst)
+}
@@ -0,0 +1,114 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Bulk item operations.
+const (
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+	BulkMove   = "move"
+	BulkTag    = "tag"
+)
+
+const MaxBulkItems = 500
+
+// Per-item outcomes reported by a bulk operation.
+const (
+	BulkStatusOk         = "ok"
+	BulkStatusFailed     = "failed"
+	BulkStatusRolledBack = "rolled_back"
+)
+
+// BulkItemInput applies one operation to many items at once. Update holds
+// the patch for the update operation, ListId the target of move, and
+// AddTags and RemoveTags the changes made by tag.
+type BulkItemInput struct {
+	Ids        []int            `json:"ids" binding:"required"`
+	Operation  string           `json:"operation" binding:"required"`
+	Update     *UpdateItemInput `json:"update"`
+	ListId     *int             `json:"list_id"`
+	AddTags    []string         `json:"add_tags"`
+	RemoveTags []string         `json:"remove_tags"`
+}
+
+func (i BulkItemInput) Validate() error {
+	var verr ValidationError
+	switch {
+	case len(i.Ids) == 0:
+		verr.Add("ids", "must not be empty")
+	case len(i.Ids) > MaxBulkItems:
+		verr.Add("ids", fmt.Sprintf("must contain at most %d ids", MaxBulkItems))
+	default:
+		seen := make(map[int]bool, len(i.Ids))
+		for _, id := range i.Ids {
+			if seen[id] {
+				verr.Add("ids", "must not contain duplicates")
+				break
+			}
+			seen[id] = true
+		}
+	}
+
+	switch i.Operation {
+	case BulkUpdate:
+		if i.Update == nil {
+			verr.Add("update", "is required for the update operation")
+			break
+		}
+		var uerr *ValidationError
+		if err := i.Update.Validate(); errors.As(err, &uerr) {
+			for field, message := range uerr.Fields {
+				verr.Add("update."+field, message)
+			}
+		} else if err != nil {
+			verr.Add("update", "must set at least one field")
+		}
+	case BulkMove:
+		if i.ListId == nil {
+			verr.Add("list_id", "is required for the move operation")
+		}
+	case BulkTag:
+		if len(i.AddTags) == 0 && len(i.RemoveTags) == 0 {
+			verr.Add("add_tags", "is required for the tag operation unless remove_tags is set")
+		}
+		validateTags(&verr, "add_tags", i.AddTags)
+		validateTags(&verr, "remove_tags", i.RemoveTags)
+	case BulkDelete:
+	default:
+		verr.Add("operation", "must be one of update, delete, move or tag")
+	}
+
+	return verr.OrNil()
+}
+
+// BulkItemResult reports the outcome of a bulk operation for one item.
+type BulkItemResult struct {
+	Id     int    `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkError is returned when a bulk operation failed for some items and
+// was rolled back as a whole. Results reports every item. It matches
+// ErrConflict with errors.Is.
+type BulkError struct {
+	Results []BulkItemResult
+}
+
+func (e *BulkError) Error() string {
+	failed := 0
+	for _, result := range e.Results {
+		if result.Status == BulkStatusFailed {
+			failed++
+		}
+	}
+
+	return fmt.Sprintf("%s: bulk operation failed for %d of %d items and was rolled back", ErrConflict, failed, len(e.Results))
+}
+
+func (e *BulkError) Unwrap() error {
+	return ErrConflict
+}
@@ -2,13 +2,17 @@ package main
 
 import (
 	"context"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/MyNameIsWhaaat/todo-app"
 	"github.com/MyNameIsWhaaat/todo-app/pkg/handler"
 	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/rpc"
 	"github.com/MyNameIsWhaaat/todo-app/pkg/service"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
@@ -16,6 +20,9 @@ import (
 	"github.com/spf13/viper"
 )
 
+//go:generate sh -c "cd .. && swag init -g cmd/main.go -o docs --parseExtension api-v1"
+//go:generate sh -c "cd .. && swag init -g version.go -d pkg/handler,./ -o docs/v2 --instanceName v2 --parseExtension api-v2"
+
 // @title Todo App API
 // @version 1.0
 // @description API Server for TodoList Application
@@ -38,22 +45,50 @@ func main() {
 		logrus.Fatalf("error loading env variables: %s", err.Error())
 	}
 
-	db, err := repository.NewPostgresDB(repository.Config{
+	dbConfig := repository.Config{
 		Host: viper.GetString("db.host"),
 		Port: viper.GetString("db.port"),
 		Username: viper.GetString("db.username"),
 		DBName: viper.GetString("db.dbname"),
 		SSLMode: viper.GetString("db.sslmode"),
 		Password: os.Getenv("DB_PASSWORD"),
-	})
+	}
+
+	cursorKey := os.Getenv("CURSOR_SIGNING_KEY")
+	if cursorKey == ""{
+		logrus.Fatalf("error loading env variables: CURSOR_SIGNING_KEY is not set")
+	}
+
+	db, err := repository.NewPostgresDB(dbConfig)
 
 	if err != nil{
 		logrus.Fatalf("failed to initialize db: %s", err.Error())
 	}
 
+	events, err := repository.NewEventListener(dbConfig)
+	if err != nil{
+		logrus.Fatalf("failed to listen for events: %s", err.Error())
+	}
+
 	repos:= repository.NewRepository(db)
 	services:= service.NewService(repos)
-	handlers := handler.NewHandler(services)
+	handlers := handler.NewHandler(services, handler.Config{
+		V1: handler.VersionPolicy{
+			DeprecatedAt: viper.GetTime("api.v1.deprecated_at"),
+			SunsetAt:     viper.GetTime("api.v1.sunset_at"),
+		},
+		CursorKey: []byte(cursorKey),
+		TrustedProxies: viper.GetStringSlice("http.trusted_proxies"),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go services.Trash.RunPurge(ctx, getDuration("trash.purge_interval"), getDuration("trash.retention"))
+	go services.Idempotency.RunPurge(ctx, getDuration("idempotency.purge_interval"))
+	go services.Events.Run(ctx, events)
+	go services.Webhook.RunDelivery(ctx, getDuration("webhooks.delivery_interval"))
+	go services.Outbox.RunRelay(ctx, getDuration("outbox.relay_interval"), getDuration("outbox.retention"))
+
+	grpcServer := rpc.NewServer(services)
 
 	srv := new(todo.Server)
 
@@ -63,6 +98,16 @@ func main() {
 		}
 	}()
 
+	go func(){
+		listener, err := net.Listen("tcp", ":"+viper.GetString("grpc.port"))
+		if err != nil{
+			logrus.Fatalf("error occures while listening for grpc: %s", err.Error())
+		}
+		if err := grpcServer.Serve(listener); err != nil{
+			logrus.Fatalf("error occures while running grpc server: %s", err.Error())
+		}
+	}()
+
 	logrus.Printf("TodoApp started")
 
 	quit := make(chan os.Signal, 1)
@@ -71,10 +116,22 @@ func main() {
 
 	logrus.Printf("TodoApp Shutting Down")
 
+	cancel()
+
 	if err := srv.Shutdown(context.Background());err != nil{
 		logrus.Errorf("error occured on server shutting down: %s", err.Error())
 	}
+
+	grpcCtx, grpcCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer grpcCancel()
+	if err := rpc.Shutdown(grpcCtx, grpcServer);err != nil{
+		logrus.Errorf("error occured on grpc server shutting down: %s", err.Error())
+	}
 	
+	if err := events.Close();err != nil{
+		logrus.Errorf("error occured on event listener close: %s", err.Error())
+	}
+
 	if err := db.Close();err != nil{
 		logrus.Errorf("error occured on db connection close: %s", err.Error())
 	}
@@ -84,5 +141,24 @@ func main() {
 func initConfig() error{
 	viper.AddConfigPath("configs")
 	viper.SetConfigName("config")
+
+	viper.SetDefault("trash.retention", 720*time.Hour)
+	viper.SetDefault("trash.purge_interval", time.Hour)
+	viper.SetDefault("idempotency.purge_interval", time.Hour)
+	viper.SetDefault("webhooks.delivery_interval", 5*time.Second)
+	viper.SetDefault("outbox.relay_interval", 500*time.Millisecond)
+	viper.SetDefault("outbox.retention", 24*time.Hour)
+
 	return viper.ReadInConfig()
+}
+
+// getDuration returns a duration setting, which has to be positive: the
+// background jobs tick at these intervals and a ticker cannot tick at zero.
+func getDuration(key string) time.Duration{
+	duration := viper.GetDuration(key)
+	if duration <= 0{
+		logrus.Fatalf("invalid %s: must be a positive duration, got %q", key, viper.GetString(key))
+	}
+
+	return duration
 }
\ No newline at end of file
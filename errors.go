@@ -0,0 +1,63 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Domain errors returned by the service layer. Repositories translate
+// driver errors into them and handlers map them onto HTTP statuses.
+var (
+	ErrNotFound   = errors.New("resource not found")
+	ErrForbidden  = errors.New("access denied")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+	// ErrPreconditionFailed is returned when a change is conditioned on a
+	// version of the resource that is no longer current.
+	ErrPreconditionFailed = errors.New("resource has been modified")
+
+	ErrListArchived = fmt.Errorf("%w: list is archived", ErrConflict)
+)
+
+// ValidationError reports which input fields are invalid and why.
+// It matches ErrValidation with errors.Is.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Add(field, message string) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]string)
+	}
+	e.Fields[field] = message
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+": "+e.Fields[name])
+	}
+
+	return ErrValidation.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// OrNil returns the error only when at least one field failed.
+func (e *ValidationError) OrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+
+	return e
+}
@@ -0,0 +1,57 @@
+package todo
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// Change events published to the members of a list.
+const (
+	EventListCreated    = "list.created"
+	EventListUpdated    = "list.updated"
+	EventListDeleted    = "list.deleted"
+	EventListArchived   = "list.archived"
+	EventListUnarchived = "list.unarchived"
+	EventListRestored   = "list.restored"
+	EventItemCreated    = "item.created"
+	EventItemUpdated    = "item.updated"
+	// EventItemCompleted follows the item.updated event of an update that
+	// marked the item done.
+	EventItemCompleted = "item.completed"
+	EventItemDeleted   = "item.deleted"
+	EventItemRestored  = "item.restored"
+	// EventResync tells subscribers that events may have been missed and
+	// the list should be fetched again.
+	EventResync = "resync"
+)
+
+// Event is a change made to a list or one of its items. It carries ids
+// only; subscribers fetch what they need. Events are delivered at least
+// once; Id is unique per event, so consumers drop the ones they have seen.
+type Event struct {
+	Id      string    `json:"id,omitempty"`
+	Type    string    `json:"type"`
+	ListId  int       `json:"list_id"`
+	ItemId  int       `json:"item_id,omitempty"`
+	ActorId int       `json:"actor_id,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+func NewListEvent(eventType string, actorId, listId int) Event {
+	return Event{Id: NewEventId(), Type: eventType, ListId: listId, ActorId: actorId, At: time.Now().UTC()}
+}
+
+func NewItemEvent(eventType string, actorId, listId, itemId int) Event {
+	return Event{Id: NewEventId(), Type: eventType, ListId: listId, ItemId: itemId, ActorId: actorId, At: time.Now().UTC()}
+}
+
+// NewEventId returns a random (version 4) UUID.
+func NewEventId() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
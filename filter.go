@@ -0,0 +1,25 @@
+package todo
+
+import "time"
+
+const maxFilterQueryLength = 255
+
+// ItemFilter narrows down the items of a list. Nil fields are not applied.
+type ItemFilter struct {
+	Done      *bool
+	DueBefore *time.Time
+	DueAfter  *time.Time
+	Query     string
+}
+
+func (f ItemFilter) Validate() error {
+	var verr ValidationError
+	if f.DueBefore != nil && f.DueAfter != nil && !f.DueAfter.Before(*f.DueBefore) {
+		verr.Add("due_after", "must be earlier than due_before")
+	}
+	if len(f.Query) > maxFilterQueryLength {
+		verr.Add("q", "must be at most 255 characters long")
+	}
+
+	return verr.OrNil()
+}
@@ -0,0 +1,31 @@
+package todo
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	IdempotencyKeyTTL       = 24 * time.Hour
+	MaxIdempotencyKeyLength = 255
+
+	// IdempotencyKeyLease is how long a key stays reserved for a request
+	// that has not completed. A request that dies without releasing its
+	// key holds it up for that long, not for the whole TTL.
+	IdempotencyKeyLease = time.Minute
+)
+
+var (
+	ErrIdempotencyKeyReused     = fmt.Errorf("%w: idempotency key was already used with a different request", ErrValidation)
+	ErrIdempotencyKeyInProgress = fmt.Errorf("%w: a request with this idempotency key is still in progress", ErrConflict)
+)
+
+// IdempotencyRecord is a request made with an Idempotency-Key. Status is 0
+// until the response has been stored.
+type IdempotencyRecord struct {
+	Fingerprint string `db:"fingerprint"`
+	Status      int    `db:"status"`
+	ContentType string `db:"content_type"`
+	Location    string `db:"location"`
+	Body        []byte `db:"body"`
+}
@@ -0,0 +1,39 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Nullable is an update field for a column that may be set to null. Set
+// reports whether the field was present in the input; Value is nil when it
+// was given as null.
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// NewNullable returns a present field holding v, which may be nil.
+func NewNullable[T any](v *T) Nullable[T] {
+	return Nullable[T]{Set: true, Value: v}
+}
+
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		n.Value = nil
+		return nil
+	}
+
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	n.Value = &v
+
+	return nil
+}
+
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.Value)
+}
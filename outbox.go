@@ -0,0 +1,16 @@
+package todo
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxMessage is an event written to the outbox together with the change
+// it describes, waiting to be relayed.
+type OutboxMessage struct {
+	Id        int64           `db:"id"`
+	EventId   string          `db:"event_id"`
+	Payload   json.RawMessage `db:"payload"`
+	Attempts  int             `db:"attempts"`
+	CreatedAt time.Time       `db:"created_at"`
+}
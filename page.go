@@ -0,0 +1,32 @@
+package todo
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+// Page selects a window of a collection ordered by id. Rows with an id
+// greater than AfterId are returned, at most Limit of them; a zero Limit
+// means no limit.
+type Page struct {
+	Limit   int
+	AfterId int
+}
+
+// Normalize applies the default limit and validates the page requested
+// by a client.
+func (p Page) Normalize() (Page, error) {
+	if p.Limit == 0 {
+		p.Limit = DefaultPageLimit
+	}
+
+	var verr ValidationError
+	if p.Limit < 0 || p.Limit > MaxPageLimit {
+		verr.Add("limit", "must be between 1 and 100")
+	}
+	if p.AfterId < 0 {
+		verr.Add("cursor", "must not be negative")
+	}
+
+	return p, verr.OrNil()
+}
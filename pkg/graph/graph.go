@@ -0,0 +1,89 @@
+// Package graph serves the GraphQL API over the service layer.
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/service"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/sirupsen/logrus"
+)
+
+// maxDepth bounds how deeply a query may nest selections.
+const maxDepth = 8
+
+//go:embed schema.graphql
+var schemaString string
+
+var schema = graphql.MustParseSchema(schemaString, &Resolver{}, graphql.MaxDepth(maxDepth))
+
+// Request is a GraphQL request as posted over HTTP.
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type contextKey struct{}
+
+// requestContext is what the resolvers of one request share: the user it is
+// made for, the services to run it with and the loaders batching its reads.
+type requestContext struct {
+	userId   int
+	services *service.Service
+	loaders  *loaders
+}
+
+// Exec runs the request for the user.
+func Exec(ctx context.Context, services *service.Service, userId int, request Request) *graphql.Response {
+	ctx = context.WithValue(ctx, contextKey{}, &requestContext{
+		userId:   userId,
+		services: services,
+		loaders:  newLoaders(services, userId),
+	})
+
+	return schema.Exec(ctx, request.Query, request.OperationName, request.Variables)
+}
+
+func fromContext(ctx context.Context) *requestContext {
+	return ctx.Value(contextKey{}).(*requestContext)
+}
+
+// resolverError is reported in the errors of a response with a stable code
+// in its extensions.
+type resolverError struct {
+	code    string
+	message string
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+func (e *resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+// resolveError maps a service error onto what the client is told. Errors
+// that are not recognised are logged and reported without their text.
+func resolveError(err error) error {
+	var verr *todo.ValidationError
+	switch {
+	case errors.As(err, &verr), errors.Is(err, todo.ErrValidation):
+		return &resolverError{code: "VALIDATION_FAILED", message: err.Error()}
+	case errors.Is(err, todo.ErrNotFound):
+		return &resolverError{code: "NOT_FOUND", message: err.Error()}
+	case errors.Is(err, todo.ErrForbidden):
+		return &resolverError{code: "FORBIDDEN", message: err.Error()}
+	case errors.Is(err, todo.ErrPreconditionFailed):
+		return &resolverError{code: "PRECONDITION_FAILED", message: err.Error()}
+	case errors.Is(err, todo.ErrConflict):
+		return &resolverError{code: "CONFLICT", message: err.Error()}
+	default:
+		logrus.Errorf("graphql resolver failed: %s", err.Error())
+		return &resolverError{code: "INTERNAL", message: "internal error"}
+	}
+}
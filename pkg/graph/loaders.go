@@ -0,0 +1,128 @@
+package graph
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/service"
+	"github.com/graph-gophers/dataloader"
+)
+
+// loaders batch the reads the resolvers of one request make by list id, so
+// that nested fields of n lists cost one query rather than n.
+type loaders struct {
+	lists   *dataloader.Loader
+	items   *dataloader.Loader
+	members *dataloader.Loader
+}
+
+func newLoaders(services *service.Service, userId int) *loaders {
+	return &loaders{
+		lists: dataloader.NewBatchedLoader(func(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+			lists, err := services.TodoList.GetByIds(userId, keyIds(keys))
+			if err != nil {
+				return failAll(keys, err)
+			}
+
+			byId := make(map[int]todo.TodoList, len(lists))
+			for _, list := range lists {
+				byId[list.Id] = list
+			}
+
+			results := make([]*dataloader.Result, len(keys))
+			for i, id := range keyIds(keys) {
+				list, ok := byId[id]
+				if !ok {
+					results[i] = &dataloader.Result{Error: todo.ErrNotFound}
+					continue
+				}
+				results[i] = &dataloader.Result{Data: list}
+			}
+			return results
+		}),
+		items: dataloader.NewBatchedLoader(func(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+			items, err := services.TodoItem.GetByLists(userId, keyIds(keys))
+			if err != nil {
+				return failAll(keys, err)
+			}
+
+			byList := make(map[int][]todo.TodoItem, len(keys))
+			for _, item := range items {
+				byList[item.ListId] = append(byList[item.ListId], item)
+			}
+
+			results := make([]*dataloader.Result, len(keys))
+			for i, id := range keyIds(keys) {
+				results[i] = &dataloader.Result{Data: byList[id]}
+			}
+			return results
+		}),
+		members: dataloader.NewBatchedLoader(func(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+			members, err := services.TodoList.GetMembers(userId, keyIds(keys))
+			if err != nil {
+				return failAll(keys, err)
+			}
+
+			byList := make(map[int][]todo.ListMember, len(keys))
+			for _, member := range members {
+				byList[member.ListId] = append(byList[member.ListId], member)
+			}
+
+			results := make([]*dataloader.Result, len(keys))
+			for i, id := range keyIds(keys) {
+				results[i] = &dataloader.Result{Data: byList[id]}
+			}
+			return results
+		}),
+	}
+}
+
+func (l *loaders) list(ctx context.Context, listId int) (todo.TodoList, error) {
+	data, err := l.lists.Load(ctx, idKey(listId))()
+	if err != nil {
+		return todo.TodoList{}, err
+	}
+
+	return data.(todo.TodoList), nil
+}
+
+func (l *loaders) listItems(ctx context.Context, listId int) ([]todo.TodoItem, error) {
+	data, err := l.items.Load(ctx, idKey(listId))()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.([]todo.TodoItem), nil
+}
+
+func (l *loaders) listMembers(ctx context.Context, listId int) ([]todo.ListMember, error) {
+	data, err := l.members.Load(ctx, idKey(listId))()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.([]todo.ListMember), nil
+}
+
+func idKey(id int) dataloader.Key {
+	return dataloader.StringKey(strconv.Itoa(id))
+}
+
+func keyIds(keys dataloader.Keys) []int {
+	ids := make([]int, len(keys))
+	for i, key := range keys {
+		ids[i], _ = strconv.Atoi(key.String())
+	}
+
+	return ids
+}
+
+func failAll(keys dataloader.Keys, err error) []*dataloader.Result {
+	results := make([]*dataloader.Result, len(keys))
+	for i := range keys {
+		results[i] = &dataloader.Result{Error: err}
+	}
+
+	return results
+}
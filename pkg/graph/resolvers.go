@@ -0,0 +1,455 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// Resolver is the root resolver. It holds no state; everything a request
+// needs comes with its context.
+type Resolver struct{}
+
+type listsArgs struct {
+	Archived bool
+	Limit    int32
+	After    *graphql.ID
+}
+
+func (r *Resolver) Lists(ctx context.Context, args listsArgs) (*listPageResolver, error) {
+	rc := fromContext(ctx)
+
+	page := todo.Page{Limit: int(args.Limit)}
+	if args.After != nil {
+		afterId, err := parseId("after", *args.After)
+		if err != nil {
+			return nil, err
+		}
+		page.AfterId = afterId
+	}
+
+	lists, more, err := rc.services.TodoList.GetAll(rc.userId, args.Archived, page)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	for _, list := range lists {
+		rc.loaders.lists.Prime(ctx, idKey(list.Id), list)
+	}
+
+	return &listPageResolver{lists: lists, more: more}, nil
+}
+
+func (r *Resolver) List(ctx context.Context, args struct{ Id graphql.ID }) (*listResolver, error) {
+	rc := fromContext(ctx)
+
+	listId, err := parseId("id", args.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := rc.services.TodoList.GetById(rc.userId, listId)
+	if errors.Is(err, todo.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	return &listResolver{list}, nil
+}
+
+func (r *Resolver) Item(ctx context.Context, args struct{ Id graphql.ID }) (*itemResolver, error) {
+	rc := fromContext(ctx)
+
+	itemId, err := parseId("id", args.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := rc.services.TodoItem.GetById(rc.userId, itemId)
+	if errors.Is(err, todo.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	return &itemResolver{item}, nil
+}
+
+type createListArgs struct {
+	Input struct {
+		Title       string
+		Description *string
+	}
+}
+
+func (r *Resolver) CreateList(ctx context.Context, args createListArgs) (*listResolver, error) {
+	rc := fromContext(ctx)
+
+	list, err := rc.services.TodoList.Create(rc.userId, todo.TodoList{
+		Title:       args.Input.Title,
+		Description: args.Input.Description,
+	})
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	return &listResolver{list}, nil
+}
+
+type updateListArgs struct {
+	Id    graphql.ID
+	Input struct {
+		Title       *string
+		Description graphql.NullString
+	}
+	Version *int32
+}
+
+func (r *Resolver) UpdateList(ctx context.Context, args updateListArgs) (*listResolver, error) {
+	rc := fromContext(ctx)
+
+	listId, err := parseId("id", args.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	input := todo.UpdateListInput{
+		Title:       args.Input.Title,
+		Description: todo.Nullable[string]{Set: args.Input.Description.Set, Value: args.Input.Description.Value},
+	}
+	if err := rc.services.TodoList.Update(rc.userId, listId, input, version(args.Version)); err != nil {
+		return nil, resolveError(err)
+	}
+
+	list, err := rc.services.TodoList.GetById(rc.userId, listId)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	return &listResolver{list}, nil
+}
+
+type deleteArgs struct {
+	Id      graphql.ID
+	Version *int32
+}
+
+func (r *Resolver) DeleteList(ctx context.Context, args deleteArgs) (bool, error) {
+	rc := fromContext(ctx)
+
+	listId, err := parseId("id", args.Id)
+	if err != nil {
+		return false, err
+	}
+
+	if err := rc.services.TodoList.Delete(rc.userId, listId, version(args.Version)); err != nil {
+		return false, resolveError(err)
+	}
+
+	return true, nil
+}
+
+type createItemArgs struct {
+	ListId graphql.ID
+	Input  struct {
+		Title       string
+		Description *string
+		DueDate     *graphql.Time
+		Priority    int32
+		Tags        *[]string
+		Recurrence  *string
+	}
+}
+
+func (r *Resolver) CreateItem(ctx context.Context, args createItemArgs) (*itemResolver, error) {
+	rc := fromContext(ctx)
+
+	listId, err := parseId("listId", args.ListId)
+	if err != nil {
+		return nil, err
+	}
+
+	item := todo.TodoItem{
+		Title:       args.Input.Title,
+		Description: args.Input.Description,
+		Priority:    int(args.Input.Priority),
+	}
+	if args.Input.DueDate != nil {
+		item.DueDate = &args.Input.DueDate.Time
+	}
+	if args.Input.Tags != nil {
+		item.Tags = *args.Input.Tags
+	}
+	if args.Input.Recurrence != nil {
+		item.Recurrence = *args.Input.Recurrence
+	}
+
+	item, err = rc.services.TodoItem.Create(rc.userId, listId, item)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	return &itemResolver{item}, nil
+}
+
+type updateItemArgs struct {
+	Id    graphql.ID
+	Input struct {
+		Title       *string
+		Description graphql.NullString
+		Done        *bool
+		DueDate     graphql.NullTime
+		Priority    *int32
+		Tags        *[]string
+		Recurrence  *string
+	}
+	Version *int32
+}
+
+func (r *Resolver) UpdateItem(ctx context.Context, args updateItemArgs) (*itemResolver, error) {
+	rc := fromContext(ctx)
+
+	itemId, err := parseId("id", args.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	input := todo.UpdateItemInput{
+		Title:       args.Input.Title,
+		Description: todo.Nullable[string]{Set: args.Input.Description.Set, Value: args.Input.Description.Value},
+		Done:        args.Input.Done,
+		DueDate:     todo.Nullable[time.Time]{Set: args.Input.DueDate.Set},
+		Tags:        args.Input.Tags,
+		Recurrence:  args.Input.Recurrence,
+	}
+	if args.Input.DueDate.Value != nil {
+		input.DueDate.Value = &args.Input.DueDate.Value.Time
+	}
+	if args.Input.Priority != nil {
+		priority := int(*args.Input.Priority)
+		input.Priority = &priority
+	}
+
+	if err := rc.services.TodoItem.Update(rc.userId, itemId, input, version(args.Version)); err != nil {
+		return nil, resolveError(err)
+	}
+
+	item, err := rc.services.TodoItem.GetById(rc.userId, itemId)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	return &itemResolver{item}, nil
+}
+
+func (r *Resolver) DeleteItem(ctx context.Context, args deleteArgs) (bool, error) {
+	rc := fromContext(ctx)
+
+	itemId, err := parseId("id", args.Id)
+	if err != nil {
+		return false, err
+	}
+
+	if err := rc.services.TodoItem.Delete(rc.userId, itemId, version(args.Version)); err != nil {
+		return false, resolveError(err)
+	}
+
+	return true, nil
+}
+
+type listPageResolver struct {
+	lists []todo.TodoList
+	more  bool
+}
+
+func (r *listPageResolver) Nodes() []*listResolver {
+	resolvers := make([]*listResolver, len(r.lists))
+	for i, list := range r.lists {
+		resolvers[i] = &listResolver{list}
+	}
+
+	return resolvers
+}
+
+func (r *listPageResolver) EndCursor() *graphql.ID {
+	if !r.more {
+		return nil
+	}
+
+	cursor := graphqlId(r.lists[len(r.lists)-1].Id)
+	return &cursor
+}
+
+type listResolver struct {
+	list todo.TodoList
+}
+
+func (r *listResolver) Id() graphql.ID {
+	return graphqlId(r.list.Id)
+}
+
+func (r *listResolver) Title() string {
+	return r.list.Title
+}
+
+func (r *listResolver) Description() *string {
+	return r.list.Description
+}
+
+func (r *listResolver) ArchivedAt() *graphql.Time {
+	return graphqlTime(r.list.ArchivedAt)
+}
+
+func (r *listResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.list.CreatedAt}
+}
+
+func (r *listResolver) Version() int32 {
+	return int32(r.list.Version)
+}
+
+func (r *listResolver) Items(ctx context.Context, args struct{ Done *bool }) ([]*itemResolver, error) {
+	items, err := fromContext(ctx).loaders.listItems(ctx, r.list.Id)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	resolvers := make([]*itemResolver, 0, len(items))
+	for _, item := range items {
+		if args.Done != nil && item.Done != *args.Done {
+			continue
+		}
+		resolvers = append(resolvers, &itemResolver{item})
+	}
+
+	return resolvers, nil
+}
+
+func (r *listResolver) Members(ctx context.Context) ([]*memberResolver, error) {
+	members, err := fromContext(ctx).loaders.listMembers(ctx, r.list.Id)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	resolvers := make([]*memberResolver, len(members))
+	for i, member := range members {
+		resolvers[i] = &memberResolver{member}
+	}
+
+	return resolvers, nil
+}
+
+type itemResolver struct {
+	item todo.TodoItem
+}
+
+func (r *itemResolver) Id() graphql.ID {
+	return graphqlId(r.item.Id)
+}
+
+func (r *itemResolver) List(ctx context.Context) (*listResolver, error) {
+	list, err := fromContext(ctx).loaders.list(ctx, r.item.ListId)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	return &listResolver{list}, nil
+}
+
+func (r *itemResolver) Title() string {
+	return r.item.Title
+}
+
+func (r *itemResolver) Description() *string {
+	return r.item.Description
+}
+
+func (r *itemResolver) Done() bool {
+	return r.item.Done
+}
+
+func (r *itemResolver) DueDate() *graphql.Time {
+	return graphqlTime(r.item.DueDate)
+}
+
+func (r *itemResolver) Priority() int32 {
+	return int32(r.item.Priority)
+}
+
+func (r *itemResolver) Tags() []string {
+	if r.item.Tags == nil {
+		return []string{}
+	}
+
+	return r.item.Tags
+}
+
+func (r *itemResolver) Recurrence() *string {
+	if r.item.Recurrence == "" {
+		return nil
+	}
+
+	return &r.item.Recurrence
+}
+
+func (r *itemResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.item.CreatedAt}
+}
+
+func (r *itemResolver) Version() int32 {
+	return int32(r.item.Version)
+}
+
+type memberResolver struct {
+	member todo.ListMember
+}
+
+func (r *memberResolver) Id() graphql.ID {
+	return graphqlId(r.member.Id)
+}
+
+func (r *memberResolver) Name() string {
+	return r.member.Name
+}
+
+func (r *memberResolver) Username() string {
+	return r.member.Username
+}
+
+func parseId(field string, id graphql.ID) (int, error) {
+	value, err := strconv.Atoi(string(id))
+	if err != nil || value < 1 {
+		return 0, &resolverError{code: "VALIDATION_FAILED", message: field + " must be a positive integer"}
+	}
+
+	return value, nil
+}
+
+func graphqlId(id int) graphql.ID {
+	return graphql.ID(strconv.Itoa(id))
+}
+
+func graphqlTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+
+	return &graphql.Time{Time: *t}
+}
+
+// version turns an optional expected version into the 0-means-any form the
+// services take.
+func version(v *int32) int {
+	if v == nil {
+		return 0
+	}
+
+	return int(*v)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type getAuditEntriesResponse struct {
+	Data       []todo.AuditEntry `json:"data"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// @Summary Get list activity
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Retrieves who changed the list and its items and how, oldest first. Before and after hold the fields a change touched
+// @ID get-list-activity
+// @Accept json
+// @Produce json
+// @Param id path int true "List ID"
+// @Param limit query int false "Page size, 50 by default and at most 100"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} getAuditEntriesResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/lists/{id}/activity [get]
+// @x-api-v1 true
+func (h *Handler) getListActivity(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	page, err := h.getPage(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	entries, more, err := h.requestServices(c).Audit.GetListActivity(userId, id, page)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.auditEntriesResponse(c, entries, more))
+}
+
+// @Summary Query audit log
+// @Security ApiKeyAuth
+// @Tags admin
+// @Description Retrieves the audit entries of all users matching the filters, oldest first. Administrators only
+// @ID query-audit-log
+// @Accept json
+// @Produce json
+// @Param actor_id query int false "Only changes made by this user"
+// @Param action query string false "Only changes of this action, such as item.updated"
+// @Param entity query string false "Only changes to lists or items" Enums(list, item)
+// @Param entity_id query int false "Only changes to this list or item; requires entity"
+// @Param list_id query int false "Only changes to this list and its items"
+// @Param from query string false "Only changes made at or after this date or RFC 3339 time"
+// @Param to query string false "Only changes made before this date or RFC 3339 time"
+// @Param limit query int false "Page size, 50 by default and at most 100"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} getAuditEntriesResponse
+// @Failure 400 {object} problemResponse
+// @Failure 403 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/admin/audit [get]
+// @x-api-v1 true
+func (h *Handler) queryAuditLog(c *gin.Context) {
+	filter, err := getAuditFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, err := h.getPage(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	entries, more, err := h.requestServices(c).Audit.Query(filter, page)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.auditEntriesResponse(c, entries, more))
+}
+
+func (h *Handler) auditEntriesResponse(c *gin.Context, entries []todo.AuditEntry, more bool) getAuditEntriesResponse {
+	response := getAuditEntriesResponse{
+		Data: entries,
+	}
+	if more {
+		response.NextCursor = h.encodeCursor(c, int(entries[len(entries)-1].Id))
+	}
+
+	return response
+}
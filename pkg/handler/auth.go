@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/MyNameIsWhaaat/todo-app"
@@ -14,23 +15,26 @@ import (
 // @Accept json
 // @Produce json
 // @Param input body todo.User true "account info"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe for 24h"
 // @Success 200 {integer} integer 1
-// @Failure 400 {object} errorResponse
-// @Failure 404 {object} errorResponse
-// @Failure 500 {object} errorResponse
-// @Failure default {object} errorResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Failure default {object} problemResponse
 // @Router /auth/sign-up [post]
+// @x-api-v1 true
 func (h *Handler) signUp(c *gin.Context) {
 	var input todo.User
 
-	if err := c.BindJSON(&input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
 		return
 	}
 
-	id, err := h.services.Authorization.CreateUser(input)
+	id, err := h.requestServices(c).Authorization.CreateUser(input)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -52,22 +56,27 @@ type signInInput struct {
 // @Produce json
 // @Param input body signInInput true "credentials"
 // @Success 200 {integer} string "token"
-// @Failure 400 {object} errorResponse
-// @Failure 404 {object} errorResponse
-// @Failure 500 {object} errorResponse
-// @Failure default {object} errorResponse
+// @Failure 400 {object} problemResponse
+// @Failure 401 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Failure default {object} problemResponse
 // @Router /auth/sign-in [post]
+// @x-api-v1 true
 func (h *Handler) signIn(c *gin.Context) {
 	var input signInInput
 
-	if err := c.BindJSON(&input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
 		return
 	}
 
-	token, err := h.services.Authorization.GenerateToken(input.Username, input.Password)
+	token, err := h.requestServices(c).Authorization.GenerateToken(input.Username, input.Password)
+	if errors.Is(err, todo.ErrNotFound) {
+		c.Error(unauthorized("invalid username or password"))
+		return
+	}
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,340 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/MyNameIsWhaaat/todo-app/pkg/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxBatchRequests = 20
+	maxBatchBodySize = 1 << 20
+	batchPath        = "/api/batch"
+)
+
+// batchRefPattern matches references to fields of earlier responses such as
+// ${list.id}. A reference that makes up a whole JSON string is replaced by
+// the raw JSON value, so "${list.id}" becomes a number.
+var batchRefPattern = regexp.MustCompile(`"\$\{(\w+)\.(\w+)\}"|\$\{(\w+)\.(\w+)\}`)
+
+// batchHeaders are the request headers a sub-request may set. The others
+// are those of the batch itself.
+var batchHeaders = []string{"Content-Type", ifMatchHeader, ifNoneMatchHeader}
+
+// batchStreamPattern matches the endpoints that stream until the client
+// disconnects and so cannot be part of a batch.
+var batchStreamPattern = regexp.MustCompile(`^/api/lists/[^/]+/events(/ws)?/?$`)
+
+var errBatchRolledBack = errors.New("batch rolled back")
+
+type batchRequest struct {
+	// Transactional runs all sub-requests in one database transaction,
+	// stopping at and rolling back on the first one that fails.
+	Transactional bool              `json:"transactional"`
+	Requests      []batchSubRequest `json:"requests" binding:"required"`
+}
+
+type batchSubRequest struct {
+	// Name lets later sub-requests refer to fields of this response, as in
+	// ${name.id}.
+	Name   string `json:"name"`
+	Method string `json:"method" binding:"required"`
+	Path   string `json:"path" binding:"required"`
+	// Headers may set Content-Type, If-Match and If-None-Match. The body
+	// is sent as application/json unless Content-Type says otherwise.
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body" swaggertype:"object"`
+}
+
+type batchSubResponse struct {
+	Name   string          `json:"name,omitempty"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+}
+
+type batchResponse struct {
+	Responses  []batchSubResponse `json:"responses"`
+	RolledBack bool               `json:"rolled_back,omitempty"`
+}
+
+// @Summary Batch
+// @Security ApiKeyAuth
+// @Tags batch
+// @Description Executes up to 20 sub-requests in order with the caller's credentials. Later sub-requests may refer to fields of earlier named responses as ${name.field} in their path or body. A sub-request may set its own Content-Type, If-Match and If-None-Match headers. With transactional set, the first failing sub-request stops the batch and rolls back all of them.
+// @ID batch
+// @Accept json
+// @Produce json
+// @Param input body batchRequest true "Sub-requests"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe for 24h"
+// @Success 200 {object} batchResponse
+// @Failure 400 {object} problemResponse
+// @Failure 413 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/batch [post]
+// @x-api-v1 true
+func (h *Handler) batch(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBodySize)
+
+	var input batchRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.Error(&requestError{status: http.StatusRequestEntityTooLarge, code: codeBadRequest, detail: "batch body is too large"})
+			return
+		}
+		c.Error(bindingError(err))
+		return
+	}
+
+	if err := validateBatch(input.Requests); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if !input.Transactional {
+		responses, _ := h.runBatch(c, nil, input.Requests, false)
+		c.JSON(http.StatusOK, batchResponse{Responses: responses})
+		return
+	}
+
+	var responses []batchSubResponse
+	err := h.services.Transaction(func(services *service.Service) error {
+		var failed bool
+		responses, failed = h.runBatch(c, services, input.Requests, true)
+		if failed {
+			return errBatchRolledBack
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchRolledBack) {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batchResponse{
+		Responses:  responses,
+		RolledBack: err != nil,
+	})
+}
+
+func validateBatch(requests []batchSubRequest) error {
+	fields := make([]fieldError, 0)
+	if len(requests) == 0 {
+		fields = append(fields, fieldError{Field: "requests", Message: "must not be empty"})
+	}
+	if len(requests) > maxBatchRequests {
+		fields = append(fields, fieldError{Field: "requests", Message: fmt.Sprintf("must contain at most %d requests", maxBatchRequests)})
+	}
+
+	names := make(map[string]bool)
+	for i, r := range requests {
+		switch strings.ToUpper(r.Method) {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			fields = append(fields, fieldError{Field: fmt.Sprintf("requests[%d].method", i), Message: "must be GET, POST, PUT, PATCH or DELETE"})
+		}
+
+		if message := checkBatchPath(r.Path); message != "" {
+			fields = append(fields, fieldError{Field: fmt.Sprintf("requests[%d].path", i), Message: message})
+		}
+
+		for header := range r.Headers {
+			if !slices.Contains(batchHeaders, http.CanonicalHeaderKey(header)) {
+				fields = append(fields, fieldError{Field: fmt.Sprintf("requests[%d].headers", i), Message: "may only set " + strings.Join(batchHeaders, ", ")})
+				break
+			}
+		}
+
+		if r.Name != "" {
+			if names[r.Name] {
+				fields = append(fields, fieldError{Field: fmt.Sprintf("requests[%d].name", i), Message: "must be unique"})
+			}
+			names[r.Name] = true
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return &requestError{
+		status: http.StatusUnprocessableEntity,
+		code:   codeValidationFailed,
+		detail: "batch is invalid",
+		fields: fields,
+	}
+}
+
+// checkBatchPath reports what is wrong with the path of a sub-request, or
+// "" when nothing is. It is checked as written and again once references
+// have been substituted into it.
+func checkBatchPath(p string) string {
+	u, err := url.Parse(p)
+	switch {
+	case err != nil || u.IsAbs() || u.Host != "" || !strings.HasPrefix(u.Path, "/"):
+		return "must be an absolute path of this API"
+	case path.Clean(u.Path) != u.Path && path.Clean(u.Path)+"/" != u.Path:
+		return "must not contain . or .. segments"
+	case strings.HasPrefix(u.Path, batchPath):
+		return "must not be a batch"
+	case batchStreamPattern.MatchString(u.Path):
+		return "must not be an event stream"
+	}
+
+	return ""
+}
+
+// runBatch executes the sub-requests in order and reports whether any of
+// them failed. Unless services is nil they run with it instead of the
+// handler's own. With stopOnFailure the remaining sub-requests are skipped
+// after the first failure.
+func (h *Handler) runBatch(c *gin.Context, services *service.Service, requests []batchSubRequest, stopOnFailure bool) ([]batchSubResponse, bool) {
+	responses := make([]batchSubResponse, 0, len(requests))
+	results := make(map[string]map[string]json.RawMessage)
+	failed := false
+
+	for i, r := range requests {
+		response := batchSubResponse{Name: r.Name}
+
+		path, pathErr := resolveBatchRefs(r.Path, results, false)
+		body, bodyErr := resolveBatchRefs(string(r.Body), results, true)
+		if err := errors.Join(pathErr, bodyErr); err != nil {
+			response.Status = http.StatusFailedDependency
+			response.Body = batchProblem(c, response.Status, codeFailedDependency, err.Error())
+		} else if message := checkBatchPath(path); message != "" {
+			response.Status = http.StatusUnprocessableEntity
+			response.Body = batchProblem(c, response.Status, codeValidationFailed, "resolved path "+message)
+		} else {
+			response.Status, response.Body = h.serveBatchRequest(c, services, i, strings.ToUpper(r.Method), path, r.Headers, body)
+		}
+
+		if response.Status >= http.StatusBadRequest {
+			failed = true
+		} else if r.Name != "" {
+			var fields map[string]json.RawMessage
+			if json.Unmarshal(response.Body, &fields) == nil {
+				results[r.Name] = fields
+			}
+		}
+
+		responses = append(responses, response)
+		if failed && stopOnFailure {
+			break
+		}
+	}
+
+	return responses, failed
+}
+
+// resolveBatchRefs substitutes ${name.field} references with the fields of
+// earlier successful responses. In a path a value makes up at most one
+// segment, so values containing "/" or ".." are refused there.
+func resolveBatchRefs(s string, results map[string]map[string]json.RawMessage, inJSON bool) (string, error) {
+	var unresolved, unsafe []string
+	resolved := batchRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := batchRefPattern.FindStringSubmatch(ref)
+		quoted := m[1] != ""
+		name, field := m[1]+m[3], m[2]+m[4]
+
+		value, ok := results[name][field]
+		if !ok {
+			unresolved = append(unresolved, fmt.Sprintf("${%s.%s}", name, field))
+			return ref
+		}
+		if quoted && inJSON {
+			return string(value)
+		}
+
+		var str string
+		if json.Unmarshal(value, &str) != nil {
+			str = string(value)
+		}
+		if inJSON {
+			encoded, _ := json.Marshal(str)
+			return string(encoded[1 : len(encoded)-1])
+		}
+
+		if strings.Contains(str, "/") || strings.Contains(str, "..") {
+			unsafe = append(unsafe, fmt.Sprintf("${%s.%s}", name, field))
+			return ref
+		}
+		if quoted {
+			return `"` + url.PathEscape(str) + `"`
+		}
+		return url.PathEscape(str)
+	})
+
+	if len(unresolved) > 0 {
+		return "", fmt.Errorf("unresolved references %s", strings.Join(unresolved, ", "))
+	}
+	if len(unsafe) > 0 {
+		return "", fmt.Errorf("references %s do not resolve to a single path segment", strings.Join(unsafe, ", "))
+	}
+
+	return resolved, nil
+}
+
+// serveBatchRequest runs one sub-request through the router with the
+// caller's credentials and its own headers and records its response.
+func (h *Handler) serveBatchRequest(c *gin.Context, services *service.Service, index int, method, path string, headers map[string]string, body string) (int, json.RawMessage) {
+	ctx := c.Request.Context()
+	if services != nil {
+		ctx = context.WithValue(ctx, servicesKey{}, services)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, path, strings.NewReader(body))
+	if err != nil {
+		return http.StatusBadRequest, batchProblem(c, http.StatusBadRequest, codeBadRequest, err.Error())
+	}
+	req.RemoteAddr = c.Request.RemoteAddr
+	for _, header := range []string{"X-Forwarded-For", "X-Real-IP"} {
+		if value := c.GetHeader(header); value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+	req.Header.Set(authorizationHeader, c.GetHeader(authorizationHeader))
+	req.Header.Set(requestIdHeader, fmt.Sprintf("%s-%d", c.GetString(requestIdCtx), index))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for header, value := range headers {
+		req.Header.Set(header, value)
+	}
+
+	w := httptest.NewRecorder()
+	h.router.ServeHTTP(w, req)
+
+	raw := bytes.TrimSpace(w.Body.Bytes())
+	if len(raw) > 0 && !json.Valid(raw) {
+		raw, _ = json.Marshal(string(raw))
+	}
+
+	return w.Code, raw
+}
+
+func batchProblem(c *gin.Context, status int, code, detail string) json.RawMessage {
+	raw, _ := json.Marshal(problemResponse{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  batchPath,
+		Code:      code,
+		RequestId: c.GetString(requestIdCtx),
+	})
+
+	return raw
+}
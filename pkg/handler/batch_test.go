@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestResolveBatchRefs(t *testing.T) {
+	results := map[string]map[string]json.RawMessage{
+		"list": {
+			"id":    json.RawMessage(`7`),
+			"title": json.RawMessage(`"Chores & more"`),
+			"slash": json.RawMessage(`"/batch"`),
+			"dots":  json.RawMessage(`".."`),
+			"path":  json.RawMessage(`"%2Fevents"`),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		s       string
+		inJSON  bool
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "id in path",
+			s:    "/api/lists/${list.id}/items",
+			want: "/api/lists/7/items",
+		},
+		{
+			name: "string in path is escaped",
+			s:    "/api/search?q=${list.title}",
+			want: "/api/search?q=Chores%20&%20more",
+		},
+		{
+			name:   "whole string in body keeps its type",
+			s:      `{"list_id":"${list.id}"}`,
+			inJSON: true,
+			want:   `{"list_id":7}`,
+		},
+		{
+			name:   "string inside body text is escaped",
+			s:      `{"title":"Copy of ${list.title}"}`,
+			inJSON: true,
+			want:   `{"title":"Copy of Chores \u0026 more"}`,
+		},
+		{
+			name:   "slash is fine in a body",
+			s:      `{"title":"${list.slash}"}`,
+			inJSON: true,
+			want:   `{"title":"/batch"}`,
+		},
+		{
+			name:    "slash in path",
+			s:       "/api${list.slash}",
+			wantErr: true,
+		},
+		{
+			name:    "quoted slash in path",
+			s:       `/api/lists/"${list.slash}"`,
+			wantErr: true,
+		},
+		{
+			name:    "dot segment in path",
+			s:       "/api/lists/${list.dots}/batch",
+			wantErr: true,
+		},
+		{
+			name:    "unknown reference",
+			s:       "/api/lists/${other.id}",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveBatchRefs(tt.s, results, tt.inJSON)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveBatchRefs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveBatchRefs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// an escaped slash is decoded back when the sub-request is parsed, so
+	// the resolved path is checked again
+	resolved, err := resolveBatchRefs("/api${list.path}", results, false)
+	if err != nil {
+		t.Fatalf("resolveBatchRefs() error = %v", err)
+	}
+	if checkBatchPath(resolved) != "" {
+		t.Errorf("checkBatchPath(%q) accepted an escaped slash", resolved)
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		method  string
+		wantErr bool
+	}{
+		{name: "list", path: "/api/lists/1"},
+		{name: "v2 with query", path: "/api/v2/lists?limit=5"},
+		{name: "reference", path: "/api/lists/${list.id}/items"},
+		{name: "relative", path: "api/lists", wantErr: true},
+		{name: "absolute url", path: "http://example.com/api/lists", wantErr: true},
+		{name: "nested batch", path: "/api/batch", wantErr: true},
+		{name: "escaped nested batch", path: "/api%2Fbatch", wantErr: true},
+		{name: "dot segments", path: "/api/lists/../batch", wantErr: true},
+		{name: "event stream", path: "/api/lists/1/events", wantErr: true},
+		{name: "websocket", path: "/api/lists/1/events/ws", wantErr: true},
+		{name: "method", path: "/api/lists", method: "HEAD", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = "GET"
+			}
+
+			err := validateBatch([]batchSubRequest{{Method: method, Path: tt.path}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateBatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var reqErr *requestError
+			if err != nil && !errors.As(err, &reqErr) {
+				t.Errorf("validateBatch() error = %T, want *requestError", err)
+			}
+		})
+	}
+
+	if err := validateBatch(nil); err == nil {
+		t.Error("validateBatch() accepted an empty batch")
+	}
+}
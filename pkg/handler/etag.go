@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	etagHeader        = "ETag"
+	ifMatchHeader     = "If-Match"
+	ifNoneMatchHeader = "If-None-Match"
+)
+
+// etag renders a resource version as a strong entity tag.
+func etag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// getIfMatch returns the version a change is conditioned on by If-Match,
+// or 0 when the header is absent or "*". If-Match compares strongly, so a
+// weak tag never matches and fails the precondition.
+func getIfMatch(c *gin.Context) (int, error) {
+	value := strings.TrimSpace(c.GetHeader(ifMatchHeader))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+	if strings.HasPrefix(value, "W/") {
+		return 0, todo.ErrPreconditionFailed
+	}
+
+	version, err := strconv.Atoi(strings.Trim(value, `"`))
+	if err != nil || version < 1 {
+		return 0, badRequest("invalid If-Match header")
+	}
+
+	return version, nil
+}
+
+// notModified sets the ETag of the resource being returned and, when it
+// matches If-None-Match, answers 304 Not Modified. Handlers stop when it
+// reports true.
+func notModified(c *gin.Context, version int) bool {
+	tag := etag(version)
+	c.Header(etagHeader, tag)
+
+	for _, candidate := range strings.Split(c.GetHeader(ifNoneMatchHeader), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == tag || candidate == "*" {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// changedListETag sets the ETag of the list as it is after a change, for
+// clients to condition their next change on.
+func (h *Handler) changedListETag(c *gin.Context, userId, id int) {
+	if list, err := h.requestServices(c).TodoList.GetById(userId, id); err == nil {
+		c.Header(etagHeader, etag(list.Version))
+	}
+}
+
+// changedItemETag sets the ETag of the item as it is after a change, for
+// clients to condition their next change on.
+func (h *Handler) changedItemETag(c *gin.Context, userId, id int) {
+	if item, err := h.requestServices(c).TodoItem.GetById(userId, id); err == nil {
+		c.Header(etagHeader, etag(item.Version))
+	}
+}
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app/pkg/service"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+
+	sseKeepAliveInterval = 15 * time.Second
+	wsPingInterval       = 30 * time.Second
+	wsPongWait           = 2 * wsPingInterval
+	wsWriteWait          = 10 * time.Second
+)
+
+type eventTicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || slices.Contains(allowedOrigins, origin)
+	},
+}
+
+// @Summary Create list event ticket
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Issues a ticket that opens the event stream or WebSocket of a list when passed as the ticket query param, for browsers that cannot send the Authorization header with EventSource and WebSocket. The ticket is only good for this list, opens a single stream and expires after a minute; reconnecting takes a new one.
+// @ID create-event-ticket
+// @Produce json
+// @Param id path int true "List ID"
+// @Success 200 {object} eventTicketResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/lists/{id}/events/ticket [post]
+// @x-api-v1 true
+func (h *Handler) createEventTicket(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	if _, err := h.requestServices(c).TodoList.GetById(userId, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	ticket, err := h.requestServices(c).Authorization.GenerateEventTicket(userId, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, eventTicketResponse{
+		Ticket:    ticket,
+		ExpiresIn: int(service.EventTicketTTL.Seconds()),
+	})
+}
+
+// @Summary Stream list events
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Streams the changes made to a list and its items as server-sent events until the client disconnects. Each event is named after its type and carries the ids of what changed; a resync event means events may have been missed and the list should be fetched again. The stream ends after the list is deleted or the caller loses access to it.
+// @ID list-events
+// @Produce text/event-stream
+// @Param id path int true "List ID"
+// @Param ticket query string false "Ticket from the ticket endpoint, for clients that cannot send the Authorization header"
+// @Success 200 {object} todo.Event
+// @Failure 400 {object} problemResponse
+// @Failure 401 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/lists/{id}/events [get]
+// @x-api-v1 true
+func (h *Handler) listEvents(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	sub, err := h.requestServices(c).Events.Subscribe(userId, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer sub.Close()
+
+	// the stream outlives the server timeouts meant for plain requests
+	rc := http.NewResponseController(c.Writer)
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", eventStreamContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				logrus.Errorf("failed to encode event: %s", err.Error())
+				continue
+			}
+			if event.Id != "" {
+				fmt.Fprintf(c.Writer, "id: %s\n", event.Id)
+			}
+			fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		c.Writer.Flush()
+	}
+}
+
+// @Summary Stream list events over WebSocket
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Upgrades to a WebSocket that receives the changes made to a list and its items as JSON text messages, the same events the server-sent event stream carries. Messages sent by the client are ignored. The server closes the socket after the list is deleted or the caller loses access to it.
+// @ID list-events-ws
+// @Param id path int true "List ID"
+// @Param ticket query string false "Ticket from the ticket endpoint, for clients that cannot send the Authorization header"
+// @Success 101 {object} todo.Event
+// @Failure 400 {object} problemResponse
+// @Failure 401 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/lists/{id}/events/ws [get]
+// @x-api-v1 true
+func (h *Handler) listEventsWebSocket(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	sub, err := h.requestServices(c).Events.Subscribe(userId, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer sub.Close()
+
+	// the upgrader answers failed handshakes itself
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	closed := make(chan struct{})
+	go readWebSocket(conn, closed)
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case event, ok := <-sub.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readWebSocket discards what the client sends, answering its control
+// messages, and closes done once the connection is gone.
+func readWebSocket(conn *websocket.Conn, done chan<- struct{}) {
+	defer close(done)
+
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		if _, _, err := conn.NextReader(); err != nil {
+			return
+		}
+	}
+}
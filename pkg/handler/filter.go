@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+)
+
+const dateLayout = "2006-01-02"
+
+// getItemFilter reads the done, due_before, due_after and q query params.
+func getItemFilter(c *gin.Context) (todo.ItemFilter, error) {
+	var filter todo.ItemFilter
+
+	if value := c.Query("done"); value != "" {
+		done, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, badRequest("invalid done param")
+		}
+		filter.Done = &done
+	}
+
+	if value := c.Query("due_before"); value != "" {
+		dueBefore, err := parseTimeParam(value)
+		if err != nil {
+			return filter, badRequest("invalid due_before param")
+		}
+		filter.DueBefore = &dueBefore
+	}
+
+	if value := c.Query("due_after"); value != "" {
+		dueAfter, err := parseTimeParam(value)
+		if err != nil {
+			return filter, badRequest("invalid due_after param")
+		}
+		filter.DueAfter = &dueAfter
+	}
+
+	filter.Query = c.Query("q")
+
+	return filter, nil
+}
+
+// getAuditFilter reads the actor_id, action, entity, entity_id, list_id,
+// from and to query params.
+func getAuditFilter(c *gin.Context) (todo.AuditFilter, error) {
+	filter := todo.AuditFilter{
+		Action: c.Query("action"),
+		Entity: c.Query("entity"),
+	}
+
+	for name, dst := range map[string]**int{
+		"actor_id":  &filter.ActorId,
+		"entity_id": &filter.EntityId,
+		"list_id":   &filter.ListId,
+	} {
+		if value := c.Query(name); value != "" {
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				return filter, badRequest("invalid " + name + " param")
+			}
+			*dst = &id
+		}
+	}
+
+	for name, dst := range map[string]**time.Time{
+		"from": &filter.From,
+		"to":   &filter.To,
+	} {
+		if value := c.Query(name); value != "" {
+			t, err := parseTimeParam(value)
+			if err != nil {
+				return filter, badRequest("invalid " + name + " param")
+			}
+			*dst = &t
+		}
+	}
+
+	return filter, nil
+}
+
+// parseTimeParam accepts RFC 3339 timestamps and plain dates.
+func parseTimeParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(dateLayout, value)
+}
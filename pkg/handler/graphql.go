@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/MyNameIsWhaaat/todo-app/pkg/graph"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary GraphQL
+// @Security ApiKeyAuth
+// @Tags graphql
+// @Description Runs a GraphQL query or mutation. Lists can be fetched together with their items and members in one request; see pkg/graph/schema.graphql for the schema. Errors are reported in the errors of the response with a code in their extensions.
+// @ID graphql
+// @Accept json
+// @Produce json
+// @Param input body graph.Request true "query, operation name and variables"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problemResponse
+// @Failure 401 {object} problemResponse
+// @Router /graphql [post]
+// @x-api-v1 true
+func (h *Handler) graphql(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	var input graph.Request
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, graph.Exec(c.Request.Context(), h.servicesFor(c), userId, input))
+}
@@ -1,22 +1,45 @@
 package handler
 
 import (
+	"net/http"
+	"reflect"
+	"strings"
+
 	"github.com/MyNameIsWhaaat/todo-app/pkg/service"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/gin-contrib/cors"
-
-	"github.com/swaggo/gin-swagger" // gin-swagger middleware
-	"github.com/swaggo/files" // swagger embed files
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
 
 	_ "github.com/MyNameIsWhaaat/todo-app/docs"
 )
 
+// allowedOrigins are the browser origins allowed to call the API.
+var allowedOrigins = []string{"http://localhost:5173"}
+
+// report binding errors by their json field names; the validator is shared
+// by the whole process, so this is done once rather than per router
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "" || name == "-" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
 type Handler struct {
 	services *service.Service
+	config   Config
+	router   *gin.Engine
 }
 
-func NewHandler(services *service.Service) *Handler{
-	return &Handler{services: services}
+func NewHandler(services *service.Service, config Config) *Handler{
+	return &Handler{services: services, config: config}
 }
 
 func (h *Handler) InitRoutes() *gin.Engine {
@@ -24,46 +47,154 @@ func (h *Handler) InitRoutes() *gin.Engine {
 
 	router.RedirectTrailingSlash = false 
 
+	// the client IP is audited, so only proxies we run may forward it
+	if err := router.SetTrustedProxies(h.config.TrustedProxies); err != nil {
+		logrus.Errorf("invalid trusted proxies, trusting none: %s", err.Error())
+		router.SetTrustedProxies(nil)
+	}
+
 	// CORS Middleware с разрешением всех источников
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:5173"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowOrigins:     allowedOrigins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", requestIdHeader, ifMatchHeader, ifNoneMatchHeader, idempotencyKeyHeader},
+		ExposeHeaders:    []string{"Content-Length", "Location", requestIdHeader, etagHeader, idempotentReplayedHeader, deprecationHeader, sunsetHeader, linkHeader},
 		AllowCredentials: true,
 	}))
 
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.Use(h.requestId, h.errorHandler)
+	router.NoRoute(func(c *gin.Context) {
+		c.Error(&requestError{status: http.StatusNotFound, code: codeNotFound, detail: "route not found"})
+	})
+
+	router.GET("/swagger/*any", swaggerHandler())
 
 	auth := router.Group("auth")
 	{
-		auth.POST("/sign-up", h.signUp)
+		auth.POST("/sign-up", h.idempotent, h.signUp)
 		auth.POST("/sign-in", h.signIn)
 	}
 
-	api := router.Group("/api", h.userIdentity)
+	router.POST("/graphql", h.userIdentity, h.graphql)
+
+	// the event streams authenticate on their own so that browsers can
+	// open them with a ticket
+	events := router.Group("/api/lists/:id/events", deprecated(h.config.V1, apiV2Prefix), h.streamIdentity)
+	{
+		events.GET("", h.listEvents)
+		events.GET("/ws", h.listEventsWebSocket)
+	}
+
+	// v1 stays as it is until its sunset; new conventions go to v2
+	api := router.Group("/api", deprecated(h.config.V1, apiV2Prefix), h.userIdentity)
 	{
 		lists := api.Group("/lists")
 		{
-			lists.POST("", h.createList)  // 
+			lists.POST("", h.idempotent, h.createList)  // 
 			lists.GET("", h.getAllLists)
 			lists.GET("/:id", h.getListById)
 			lists.PUT("/:id", h.updateList)
+			lists.PATCH("/:id", h.patchList)
 			lists.DELETE("/:id", h.deleteList)
+			lists.POST("/:id/archive", h.archiveList)
+			lists.POST("/:id/unarchive", h.unarchiveList)
+			lists.POST("/:id/duplicate", h.duplicateList)
+			lists.POST("/:id/template", h.saveListAsTemplate)
+			lists.POST("/:id/events/ticket", h.createEventTicket)
+			lists.GET("/:id/activity", h.getListActivity)
 
 			items := lists.Group("/:id/items")
 			{
-				items.POST("", h.createItem)
+				items.POST("", h.idempotent, h.createItem)
 				items.GET("", h.getAllItems)
 			}
 		}
 		items := api.Group("/items")
 		{
+			items.POST("/bulk", h.idempotent, h.bulkItems)
 			items.GET("/:id", h.getItemById)
 			items.PUT("/:id", h.updateItem)
+			items.PATCH("/:id", h.patchItem)
 			items.DELETE("/:id", h.deleteItem)
+			items.GET("/:id/revisions", h.getItemRevisions)
+			items.POST("/:id/revisions/:rev/restore", h.restoreItemRevision)
+		}
+		templates := api.Group("/templates")
+		{
+			templates.GET("", h.getAllTemplates)
+			templates.GET("/:id", h.getTemplateById)
+			templates.DELETE("/:id", h.deleteTemplate)
+			templates.POST("/:id/instantiate", h.instantiateTemplate)
+		}
+		trash := api.Group("/trash")
+		{
+			trash.GET("", h.getTrash)
+			trash.POST("/:type/:id/restore", h.restoreFromTrash)
+		}
+		settings := api.Group("/settings")
+		{
+			settings.GET("", h.getSettings)
+			settings.PUT("", h.updateSettings)
+		}
+		filters := api.Group("/filters")
+		{
+			filters.POST("", h.createFilter)
+			filters.GET("", h.getAllFilters)
+			filters.GET("/:id", h.getFilterById)
+			filters.PUT("/:id", h.updateFilter)
+			filters.DELETE("/:id", h.deleteFilter)
+			filters.GET("/:id/items", h.getFilterItems)
+		}
+		webhooks := api.Group("/webhooks")
+		{
+			webhooks.POST("", h.createWebhook)
+			webhooks.GET("", h.getAllWebhooks)
+			webhooks.GET("/:id", h.getWebhookById)
+			webhooks.PUT("/:id", h.updateWebhook)
+			webhooks.DELETE("/:id", h.deleteWebhook)
+			webhooks.GET("/:id/deliveries", h.getWebhookDeliveries)
+		}
+		admin := api.Group("/admin", h.adminOnly)
+		{
+			admin.GET("/audit", h.queryAuditLog)
+		}
+		views := api.Group("/views")
+		{
+			views.GET("/today", h.getTodayView)
+			views.GET("/upcoming", h.getUpcomingView)
+			views.GET("/overdue", h.getOverdueView)
+		}
+		api.GET("/search", h.search)
+		api.POST("/quick-add", h.quickAdd)
+		api.POST("/batch", h.idempotent, h.batch)
+	}
+
+	v2 := router.Group(apiV2Prefix, h.userIdentity)
+	{
+		lists := v2.Group("/lists")
+		{
+			lists.POST("", h.idempotent, h.createListV2)
+			lists.GET("", h.getAllListsV2)
+			lists.GET("/:id", h.getListByIdV2)
+			lists.PUT("/:id", h.updateListV2)
+			lists.PATCH("/:id", h.patchListV2)
+			lists.DELETE("/:id", h.deleteListV2)
+
+			items := lists.Group("/:id/items")
+			{
+				items.POST("", h.idempotent, h.createItemV2)
+				items.GET("", h.getAllItemsV2)
+			}
+		}
+		items := v2.Group("/items")
+		{
+			items.GET("/:id", h.getItemByIdV2)
+			items.PUT("/:id", h.updateItemV2)
+			items.PATCH("/:id", h.patchItemV2)
+			items.DELETE("/:id", h.deleteItemV2)
 		}
 	}
 
+	h.router = router
 	return router
 }
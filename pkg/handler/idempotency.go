@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotentBodySize caps the bodies buffered for fingerprinting at
+	// the largest body an idempotent route accepts, that of a batch.
+	maxIdempotentBodySize = maxBatchBodySize
+)
+
+// bodyRecorder keeps a copy of the response body written through it.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotent makes a POST route safe to retry with an Idempotency-Key
+// header. The first successful response for a key is stored and replayed
+// for later requests with the same key and body; reusing the key with a
+// different request is rejected. Failed requests release the key. Keys are
+// scoped to the authenticated user. On anonymous routes there is no user to
+// scope them to, so they are scoped to the client IP instead.
+func (h *Handler) idempotent(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		c.Next()
+		return
+	}
+	if len(key) > todo.MaxIdempotencyKeyLength {
+		c.Error(badRequest("invalid Idempotency-Key header"))
+		c.Abort()
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.Error(&requestError{status: http.StatusRequestEntityTooLarge, code: codeBadRequest, detail: "request body is too large"})
+			c.Abort()
+			return
+		}
+		c.Error(badRequest("failed to read request body"))
+		c.Abort()
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.New()
+	io.WriteString(hash, c.Request.Method+" "+c.Request.URL.Path+"\n")
+	hash.Write(body)
+	fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+	userId := c.GetInt(userCtx)
+	if userId == 0 {
+		scoped := sha256.Sum256([]byte(c.ClientIP() + "\n" + key))
+		key = hex.EncodeToString(scoped[:])
+	}
+
+	record, err := h.requestServices(c).Idempotency.Begin(userId, key, fingerprint)
+	if err != nil {
+		c.Error(err)
+		c.Abort()
+		return
+	}
+	if record != nil {
+		c.Header(idempotentReplayedHeader, "true")
+		if record.Location != "" {
+			c.Header("Location", record.Location)
+		}
+		c.Data(record.Status, record.ContentType, record.Body)
+		c.Abort()
+		return
+	}
+
+	recorder := &bodyRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+
+	status := recorder.Status()
+	if len(c.Errors) > 0 || status < http.StatusOK || status >= http.StatusMultipleChoices {
+		if err := h.requestServices(c).Idempotency.Release(userId, key); err != nil {
+			logrus.Errorf("failed to release idempotency key: %s", err.Error())
+		}
+		return
+	}
+
+	response := todo.IdempotencyRecord{
+		Status:      status,
+		ContentType: recorder.Header().Get("Content-Type"),
+		Location:    recorder.Header().Get("Location"),
+		Body:        recorder.body.Bytes(),
+	}
+	if err := h.requestServices(c).Idempotency.Complete(userId, key, response); err != nil {
+		logrus.Errorf("failed to store idempotent response: %s", err.Error())
+	}
+}
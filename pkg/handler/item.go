@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -18,11 +19,15 @@ import (
 // @Produce json
 // @Param id path int true "List ID"
 // @Param input body todo.TodoItem true "Item info"
-// @Success 200 {object} todo.TodoItem
-// @Failure 400 {object} errorResponse
-// @Failure 404 {object} errorResponse
-// @Failure 500 {object} errorResponse
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe for 24h"
+// @Success 201 {object} todo.TodoItem
+// @Header 201 {string} Location "Path of the created item"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 500 {object} problemResponse
 // @Router /api/lists/{id}/items [post]
+// @x-api-v1 true
 func (h *Handler) createItem(c *gin.Context){
 	userId, err := getUserId(c)
     if err != nil {
@@ -32,27 +37,28 @@ func (h *Handler) createItem(c *gin.Context){
 
     listId, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        newErrorResponse(c, http.StatusBadRequest, "invalid list id param")
+        c.Error(badRequest("invalid list id param"))
 		return
     }
 
 	var input todo.TodoItem
-	if err := c.BindJSON(&input); err!= nil{
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	if err := c.ShouldBindJSON(&input); err!= nil{
+		c.Error(bindingError(err))
 		return
 	}
 
-	id, err := h.services.TodoItem.Create(userId, listId, input)
+	item, err := h.servicesFor(c).TodoItem.Create(userId, listId, input)
 	if err != nil{
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, map[string]interface{}{
-		"id": id,
-        "title": input.Title,
-        "description": input.Description,
-	})
+	created(c, fmt.Sprintf("/api/items/%d", item.Id), item)
+}
+
+type getAllItemsResponse struct{
+	Data       []todo.TodoItem `json:"data"`
+	NextCursor string          `json:"next_cursor,omitempty"`
 }
 
 // @Summary Get all todo list items by ID
@@ -63,11 +69,18 @@ func (h *Handler) createItem(c *gin.Context){
 // @Accept json
 // @Produce json
 // @Param list_id path int true "List ID"
-// @Success 200 {object} todo.TodoItem
-// @Failure 400 {object} errorResponse
-// @Failure 404 {object} errorResponse
-// @Failure 500 {object} errorResponse
+// @Param done query bool false "Only items with this status"
+// @Param due_before query string false "Only items due before this date or RFC 3339 time"
+// @Param due_after query string false "Only items due after this date or RFC 3339 time"
+// @Param q query string false "Text to search for in titles and descriptions"
+// @Param limit query int false "Page size, 50 by default and at most 100"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} getAllItemsResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
 // @Router /api/lists/{list_id}/items [get]
+// @x-api-v1 true
 func (h *Handler) getAllItems(c *gin.Context){
 	userId, err := getUserId(c)
     if err != nil {
@@ -77,17 +90,36 @@ func (h *Handler) getAllItems(c *gin.Context){
 
     listId, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        newErrorResponse(c, http.StatusBadRequest, "invalid list id param")
+        c.Error(badRequest("invalid list id param"))
 		return
     }
 
-	items, err := h.services.TodoItem.GetAll(userId, listId)
+	filter, err := getItemFilter(c)
+	if err != nil{
+		c.Error(err)
+		return
+	}
+
+	page, err := h.getPage(c)
+	if err != nil{
+		c.Error(err)
+		return
+	}
+
+	items, more, err := h.requestServices(c).TodoItem.GetAll(userId, listId, filter, page)
 	if err != nil{
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, items)
+	response := getAllItemsResponse{
+		Data: items,
+	}
+	if more{
+		response.NextCursor = h.encodeCursor(c, items[len(items)-1].Id)
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // @Summary Get todo list item by ID
@@ -98,11 +130,15 @@ func (h *Handler) getAllItems(c *gin.Context){
 // @Accept json
 // @Produce json
 // @Param id path int true "Item ID"
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} todo.TodoItem
-// @Failure 400 {object} errorResponse
-// @Failure 404 {object} errorResponse
-// @Failure 500 {object} errorResponse
+// @Success 304 "Not modified"
+// @Header 200 {string} ETag "Item version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
 // @Router /api/items/{id} [get]
+// @x-api-v1 true
 func (h *Handler) getItemById(c *gin.Context){
 	userId, err := getUserId(c)
     if err != nil {
@@ -112,13 +148,17 @@ func (h *Handler) getItemById(c *gin.Context){
 
     itemId, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        newErrorResponse(c, http.StatusBadRequest, "invalid list id param")
+        c.Error(badRequest("invalid list id param"))
 		return
     }
 
-	item, err := h.services.TodoItem.GetById(userId, itemId)
+	item, err := h.requestServices(c).TodoItem.GetById(userId, itemId)
 	if err != nil{
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
+		return
+	}
+
+	if notModified(c, item.Version){
 		return
 	}
 
@@ -128,17 +168,23 @@ func (h *Handler) getItemById(c *gin.Context){
 // @Summary Update todo list item
 // @Security ApiKeyAuth
 // @Tags items
-// @Description Updates a todo list item by its ID
+// @Description Replaces every writable field of a todo list item. Omitted fields are reset; use PATCH for partial updates.
 // @ID update-item
 // @Accept json
 // @Produce json
 // @Param id path int true "Item ID"
-// @Param input body todo.UpdateItemInput true "Update params"
+// @Param input body todo.TodoItem true "Full item"
+// @Param If-Match header string false "ETag the item must still have"
 // @Success 200 {object} statusResponse
-// @Failure 400 {object} errorResponse
-// @Failure 404 {object} errorResponse
-// @Failure 500 {object} errorResponse
+// @Header 200 {string} ETag "Item version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
 // @Router /api/items/{id} [put]
+// @x-api-v1 true
 func (h *Handler) updateItem(c *gin.Context){
 	userId, err := getUserId(c)
     if err != nil {
@@ -148,22 +194,29 @@ func (h *Handler) updateItem(c *gin.Context){
 
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+        c.Error(badRequest("invalid id param"))
+        return
+    }
+
+    version, err := getIfMatch(c)
+    if err != nil {
+        c.Error(err)
         return
     }
 
-    var input todo.UpdateItemInput
-    if err:= c.BindJSON(&input); err != nil{
-        newErrorResponse(c, http.StatusBadRequest, err.Error())
+    var input todo.TodoItem
+    if err:= c.ShouldBindJSON(&input); err != nil{
+        c.Error(bindingError(err))
         return
     }
 
-    if err := h.services.TodoItem.Update(userId, id, input)
+    if err := h.servicesFor(c).TodoItem.Replace(userId, id, input, version)
     err != nil{
-        newErrorResponse(c, http.StatusInternalServerError, err.Error())
+        c.Error(err)
         return
     }
 
+    h.changedItemETag(c, userId, id)
     c.JSON(http.StatusOK, statusResponse{"Ok"})
 }
 
@@ -175,11 +228,15 @@ func (h *Handler) updateItem(c *gin.Context){
 // @Accept json
 // @Produce json
 // @Param id path int true "Item ID"
+// @Param If-Match header string false "ETag the item must still have"
 // @Success 200 {object} statusResponse
-// @Failure 400 {object} errorResponse
-// @Failure 404 {object} errorResponse
-// @Failure 500 {object} errorResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 500 {object} problemResponse
 // @Router /api/items/{id} [delete]
+// @x-api-v1 true
 func (h *Handler) deleteItem(c *gin.Context){
 	userId, err := getUserId(c)
     if err != nil {
@@ -189,15 +246,66 @@ func (h *Handler) deleteItem(c *gin.Context){
 
     itemId, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        newErrorResponse(c, http.StatusBadRequest, "invalid list id param")
+        c.Error(badRequest("invalid list id param"))
 		return
     }
 
-	err = h.services.TodoItem.Delete(userId, itemId)
+	version, err := getIfMatch(c)
 	if err != nil{
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
+		return
+	}
+
+	err = h.servicesFor(c).TodoItem.Delete(userId, itemId, version)
+	if err != nil{
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, statusResponse{"ok"})
-}
\ No newline at end of file
+}
+
+type bulkItemsResponse struct {
+	Results []todo.BulkItemResult `json:"results"`
+}
+
+// @Summary Bulk item operation
+// @Security ApiKeyAuth
+// @Tags items
+// @Description Applies one operation (update, delete, move or tag) to many items in a single transaction. If any item fails nothing is changed and the 409 problem lists the outcome per item in results.
+// @ID bulk-items
+// @Accept json
+// @Produce json
+// @Param input body todo.BulkItemInput true "Item ids and operation"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe for 24h"
+// @Success 200 {object} bulkItemsResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/items/bulk [post]
+// @x-api-v1 true
+func (h *Handler) bulkItems(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	var input todo.BulkItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	results, err := h.servicesFor(c).TodoItem.Bulk(userId, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bulkItemsResponse{
+		Results: results,
+	})
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary Create todo list item
+// @Security ApiKeyAuth
+// @Tags items
+// @Description Creates a todo list item
+// @ID create-item
+// @Accept json
+// @Produce json
+// @Param id path int true "List ID"
+// @Param input body todo.TodoItem true "Item info"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe for 24h"
+// @Success 201 {object} itemEnvelope
+// @Header 201 {string} Location "Path of the created item"
+// @Header 201 {string} ETag "Item version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /lists/{id}/items [post]
+// @x-api-v2 true
+func (h *Handler) createItemV2(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid list id param"))
+		return
+	}
+
+	var input todo.TodoItem
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	item, err := h.servicesFor(c).TodoItem.Create(userId, listId, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header(etagHeader, etag(item.Version))
+	created(c, fmt.Sprintf("%s/items/%d", apiV2Prefix, item.Id), itemEnvelope{Data: item})
+}
+
+// @Summary Get all todo list items
+// @Security ApiKeyAuth
+// @Tags items
+// @Description Retrieves a page of a todo list's items
+// @ID get-all-items
+// @Produce json
+// @Param id path int true "List ID"
+// @Param done query bool false "Only items with this status"
+// @Param due_before query string false "Only items due before this date or RFC 3339 time"
+// @Param due_after query string false "Only items due after this date or RFC 3339 time"
+// @Param q query string false "Text to search for in titles and descriptions"
+// @Param limit query int false "Page size, 50 by default and at most 100"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} getAllItemsResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /lists/{id}/items [get]
+// @x-api-v2 true
+func (h *Handler) getAllItemsV2(c *gin.Context) {
+	// the v1 page is already enveloped
+	h.getAllItems(c)
+}
+
+// @Summary Get todo list item by ID
+// @Security ApiKeyAuth
+// @Tags items
+// @Description Retrieves a single todo list item by its ID
+// @ID get-item-by-id
+// @Produce json
+// @Param id path int true "Item ID"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} itemEnvelope
+// @Success 304 "Not modified"
+// @Header 200 {string} ETag "Item version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /items/{id} [get]
+// @x-api-v2 true
+func (h *Handler) getItemByIdV2(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	item, err := h.requestServices(c).TodoItem.GetById(userId, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if notModified(c, item.Version) {
+		return
+	}
+
+	c.JSON(http.StatusOK, itemEnvelope{Data: item})
+}
+
+// @Summary Replace todo list item
+// @Security ApiKeyAuth
+// @Tags items
+// @Description Replaces every writable field of a todo list item and returns the result. Omitted fields are reset; use PATCH for partial updates.
+// @ID update-item
+// @Accept json
+// @Produce json
+// @Param id path int true "Item ID"
+// @Param input body todo.TodoItem true "Full item"
+// @Param If-Match header string false "ETag the item must still have"
+// @Success 200 {object} itemEnvelope
+// @Header 200 {string} ETag "Item version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /items/{id} [put]
+// @x-api-v2 true
+func (h *Handler) updateItemV2(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input todo.TodoItem
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	if err := h.servicesFor(c).TodoItem.Replace(userId, id, input, version); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.respondItem(c, userId, id)
+}
+
+// @Summary Patch todo list item
+// @Security ApiKeyAuth
+// @Tags items
+// @Description Partially updates a todo list item with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) and returns the result. A merge patch may set description and due_date to null.
+// @ID patch-item
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "Item ID"
+// @Param input body todo.UpdateItemInput true "Merge patch, or an array of JSON Patch operations"
+// @Param If-Match header string false "ETag the item must still have"
+// @Success 200 {object} itemEnvelope
+// @Header 200 {string} ETag "Item version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 415 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /items/{id} [patch]
+// @x-api-v2 true
+func (h *Handler) patchItemV2(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.patchItemBody(c, userId, id, version); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.respondItem(c, userId, id)
+}
+
+// @Summary Delete todo list item
+// @Security ApiKeyAuth
+// @Tags items
+// @Description Moves a todo list item to the trash
+// @ID delete-item
+// @Param id path int true "Item ID"
+// @Param If-Match header string false "ETag the item must still have"
+// @Success 204 "Deleted"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /items/{id} [delete]
+// @x-api-v2 true
+func (h *Handler) deleteItemV2(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.servicesFor(c).TodoItem.Delete(userId, id, version); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondItem answers with the item as it is after a change.
+func (h *Handler) respondItem(c *gin.Context, userId, id int) {
+	item, err := h.requestServices(c).TodoItem.GetById(userId, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header(etagHeader, etag(item.Version))
+	c.JSON(http.StatusOK, itemEnvelope{Data: item})
+}
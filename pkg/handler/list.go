@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -17,12 +18,15 @@ import (
 // @Accept json
 // @Produce json
 // @Param input body todo.TodoList true "list info"
-// @Success 200 {integer} integer
-// @Failure 400 {object} errorResponse
-// @Failure 404 {object} errorResponse
-// @Failure 500 {object} errorResponse
-// @Failure default {object} errorResponse
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe for 24h"
+// @Success 201 {object} todo.TodoList
+// @Header 201 {string} Location "Path of the created list"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Failure default {object} problemResponse
 // @Router /api/lists [post]
+// @x-api-v1 true
 func (h *Handler) createList(c *gin.Context) {
     userId, err := getUserId(c)
     if err != nil {
@@ -31,26 +35,25 @@ func (h *Handler) createList(c *gin.Context) {
     }
 
     var input todo.TodoList
-    if err := c.BindJSON(&input); err != nil {
-        newErrorResponse(c, http.StatusBadRequest, err.Error())
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.Error(bindingError(err))
         logrus.Errorf("failed to bind JSON: %s", err.Error())
         return
     }
 
-    id, err := h.services.TodoList.Create(userId, input)
+    list, err := h.servicesFor(c).TodoList.Create(userId, input)
     if err != nil {
-        newErrorResponse(c, http.StatusInternalServerError, err.Error())
+        c.Error(err)
         logrus.Errorf("failed to create todo list: %s", err.Error())
         return
     }
 
-    c.JSON(http.StatusOK, map[string]interface{}{
-        "id": id,
-    })
+    created(c, fmt.Sprintf("/api/lists/%d", list.Id), list)
 }
 
 type getAllListsResponse struct{
-    Data []todo.TodoList `json:"data"`
+    Data       []todo.TodoList `json:"data"`
+    NextCursor string          `json:"next_cursor,omitempty"`
 }
 
 // @Summary Get all todo lists
@@ -60,11 +63,15 @@ type getAllListsResponse struct{
 // @ID get-lists
 // @Accept json
 // @Produce json
+// @Param archived query bool false "Return archived lists instead of active ones"
+// @Param limit query int false "Page size, 50 by default and at most 100"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
 // @Success 200 {object} getAllListsResponse
-// @Failure 400 {object} errorResponse
-// @Failure 404 {object} errorResponse
-// @Failure 500 {object} errorResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
 // @Router /api/lists [get]
+// @x-api-v1 true
 func (h *Handler) getAllLists(c *gin.Context){
 	userId, err := getUserId(c)
     if err != nil {
@@ -72,16 +79,36 @@ func (h *Handler) getAllLists(c *gin.Context){
         return
     }
 
-    lists, err := h.services.TodoList.GetAll(userId)
+    archived := false
+    if value := c.Query("archived"); value != "" {
+        archived, err = strconv.ParseBool(value)
+        if err != nil {
+            c.Error(badRequest("invalid archived param"))
+            return
+        }
+    }
+
+    page, err := h.getPage(c)
+    if err != nil {
+        c.Error(err)
+        return
+    }
+
+    lists, more, err := h.requestServices(c).TodoList.GetAll(userId, archived, page)
     if err != nil {
-        newErrorResponse(c, http.StatusInternalServerError, err.Error())
+        c.Error(err)
         logrus.Errorf("failed to create todo list: %s", err.Error())
         return
     }
 
-    c.JSON(http.StatusOK, getAllListsResponse{
+    response := getAllListsResponse{
         Data: lists,
-    })
+    }
+    if more {
+        response.NextCursor = h.encodeCursor(c, lists[len(lists)-1].Id)
+    }
+
+    c.JSON(http.StatusOK, response)
 }
 
 // @Summary Get todo list by ID
@@ -92,11 +119,15 @@ func (h *Handler) getAllLists(c *gin.Context){
 // @Accept json
 // @Produce json
 // @Param id path int true "List ID"
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} todo.TodoList
-// @Failure 400 {object} errorResponse
-// @Failure 404 {object} errorResponse
-// @Failure 500 {object} errorResponse
+// @Success 304 "Not modified"
+// @Header 200 {string} ETag "List version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
 // @Router /api/lists/{id} [get]
+// @x-api-v1 true
 func (h *Handler) getListById(c *gin.Context){
     userId, err := getUserId(c)
     if err != nil {
@@ -106,34 +137,44 @@ func (h *Handler) getListById(c *gin.Context){
 
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+        c.Error(badRequest("invalid id param"))
         return
     }
 
-    list, err := h.services.TodoList.GetById(userId, id)
+    list, err := h.requestServices(c).TodoList.GetById(userId, id)
     if err != nil {
-        newErrorResponse(c, http.StatusInternalServerError, err.Error())
+        c.Error(err)
         logrus.Errorf("failed to create todo list: %s", err.Error())
         return
     }
 
+    if notModified(c, list.Version) {
+        return
+    }
+
     c.JSON(http.StatusOK, list)
 }
 
 // @Summary Update todo list
 // @Security ApiKeyAuth
 // @Tags lists
-// @Description Updates a todo list by its ID
+// @Description Replaces every writable field of a todo list. Omitted fields are reset; use PATCH for partial updates.
 // @ID update-list
 // @Accept json
 // @Produce json
 // @Param id path int true "List ID"
-// @Param input body todo.UpdateListInput true "Update params"
+// @Param input body todo.TodoList true "Full list"
+// @Param If-Match header string false "ETag the list must still have"
 // @Success 200 {object} statusResponse
-// @Failure 400 {object} errorResponse
-// @Failure 404 {object} errorResponse
-// @Failure 500 {object} errorResponse
+// @Header 200 {string} ETag "List version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
 // @Router /api/lists/{id} [put]
+// @x-api-v1 true
 func (h *Handler) updateList(c *gin.Context){
 	userId, err := getUserId(c)
     if err != nil {
@@ -143,38 +184,49 @@ func (h *Handler) updateList(c *gin.Context){
 
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+        c.Error(badRequest("invalid id param"))
+        return
+    }
+
+    version, err := getIfMatch(c)
+    if err != nil {
+        c.Error(err)
         return
     }
 
-    var input todo.UpdateListInput
-    if err:= c.BindJSON(&input); err != nil{
-        newErrorResponse(c, http.StatusBadRequest, err.Error())
+    var input todo.TodoList
+    if err:= c.ShouldBindJSON(&input); err != nil{
+        c.Error(bindingError(err))
         return
     }
 
-    if err := h.services.TodoList.Update(userId, id, input)
+    if err := h.servicesFor(c).TodoList.Replace(userId, id, input, version)
     err != nil{
-        newErrorResponse(c, http.StatusInternalServerError, err.Error())
+        c.Error(err)
         return
     }
 
+    h.changedListETag(c, userId, id)
     c.JSON(http.StatusOK, statusResponse{"Ok"})
 }
 
 // @Summary Delete todo list
 // @Security ApiKeyAuth
 // @Tags lists
-// @Description Deletes a todo list by its ID
+// @Description Deletes a todo list by its ID. Archived lists are read-only and have to be unarchived first
 // @ID delete-list
 // @Accept json
 // @Produce json
 // @Param id path int true "List ID"
+// @Param If-Match header string false "ETag the list must still have"
 // @Success 200 {object} statusResponse
-// @Failure 400 {object} errorResponse
-// @Failure 404 {object} errorResponse
-// @Failure 500 {object} errorResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 500 {object} problemResponse
 // @Router /api/lists/{id} [delete]
+// @x-api-v1 true
 func (h *Handler) deleteList(c *gin.Context){
     userId, err := getUserId(c)
     if err != nil {
@@ -184,12 +236,19 @@ func (h *Handler) deleteList(c *gin.Context){
 
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+        c.Error(badRequest("invalid id param"))
+        return
+    }
+
+    version, err := getIfMatch(c)
+    if err != nil {
+        c.Error(err)
+        return
     }
 
-    err = h.services.TodoList.Delete(userId, id)
+    err = h.servicesFor(c).TodoList.Delete(userId, id, version)
     if err != nil {
-        newErrorResponse(c, http.StatusInternalServerError, err.Error())
+        c.Error(err)
         logrus.Errorf("failed to create todo list: %s", err.Error())
         return
     }
@@ -197,4 +256,127 @@ func (h *Handler) deleteList(c *gin.Context){
     c.JSON(http.StatusOK, statusResponse{
         Status: "Ok",
     })
+}
+// @Summary Duplicate todo list
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Copies a todo list with all of its items
+// @ID duplicate-list
+// @Accept json
+// @Produce json
+// @Param id path int true "List ID"
+// @Param input body todo.DuplicateListInput false "Duplicate options"
+// @Success 201 {object} todo.TodoList
+// @Header 201 {string} Location "Path of the created list"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/lists/{id}/duplicate [post]
+// @x-api-v1 true
+func (h *Handler) duplicateList(c *gin.Context){
+    userId, err := getUserId(c)
+    if err != nil {
+        logrus.Errorf("failed to get user id: %s", err.Error())
+        return
+    }
+
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.Error(badRequest("invalid id param"))
+        return
+    }
+
+    var input todo.DuplicateListInput
+    if c.Request.ContentLength != 0 {
+        if err := c.ShouldBindJSON(&input); err != nil {
+            c.Error(bindingError(err))
+            return
+        }
+    }
+
+    services := h.servicesFor(c)
+    newId, err := services.TodoList.Duplicate(userId, id, input)
+    if err != nil {
+        c.Error(err)
+        return
+    }
+
+    list, err := services.TodoList.GetById(userId, newId)
+    if err != nil {
+        c.Error(err)
+        return
+    }
+
+    created(c, fmt.Sprintf("/api/lists/%d", newId), list)
+}
+
+
+// @Summary Archive todo list
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Archives a todo list, hiding it from the default listing and making it read-only
+// @ID archive-list
+// @Accept json
+// @Produce json
+// @Param id path int true "List ID"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/lists/{id}/archive [post]
+// @x-api-v1 true
+func (h *Handler) archiveList(c *gin.Context){
+    userId, err := getUserId(c)
+    if err != nil {
+        logrus.Errorf("failed to get user id: %s", err.Error())
+        return
+    }
+
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.Error(badRequest("invalid id param"))
+        return
+    }
+
+    if err := h.servicesFor(c).TodoList.Archive(userId, id); err != nil {
+        c.Error(err)
+        return
+    }
+
+    c.JSON(http.StatusOK, statusResponse{"Ok"})
+}
+
+// @Summary Unarchive todo list
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Restores an archived todo list
+// @ID unarchive-list
+// @Accept json
+// @Produce json
+// @Param id path int true "List ID"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/lists/{id}/unarchive [post]
+// @x-api-v1 true
+func (h *Handler) unarchiveList(c *gin.Context){
+    userId, err := getUserId(c)
+    if err != nil {
+        logrus.Errorf("failed to get user id: %s", err.Error())
+        return
+    }
+
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.Error(badRequest("invalid id param"))
+        return
+    }
+
+    if err := h.servicesFor(c).TodoList.Unarchive(userId, id); err != nil {
+        c.Error(err)
+        return
+    }
+
+    c.JSON(http.StatusOK, statusResponse{"Ok"})
 }
\ No newline at end of file
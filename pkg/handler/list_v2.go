@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary Create todo list
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Creates a todo list
+// @ID create-list
+// @Accept json
+// @Produce json
+// @Param input body todo.TodoList true "list info"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe for 24h"
+// @Success 201 {object} listEnvelope
+// @Header 201 {string} Location "Path of the created list"
+// @Header 201 {string} ETag "List version"
+// @Failure 400 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /lists [post]
+// @x-api-v2 true
+func (h *Handler) createListV2(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	var input todo.TodoList
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	list, err := h.servicesFor(c).TodoList.Create(userId, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header(etagHeader, etag(list.Version))
+	created(c, fmt.Sprintf("%s/lists/%d", apiV2Prefix, list.Id), listEnvelope{Data: list})
+}
+
+// @Summary Get all todo lists
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Retrieves a page of the authenticated user's todo lists
+// @ID get-lists
+// @Produce json
+// @Param archived query bool false "Return archived lists instead of active ones"
+// @Param limit query int false "Page size, 50 by default and at most 100"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} getAllListsResponse
+// @Failure 400 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /lists [get]
+// @x-api-v2 true
+func (h *Handler) getAllListsV2(c *gin.Context) {
+	// the v1 page is already enveloped
+	h.getAllLists(c)
+}
+
+// @Summary Get todo list by ID
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Retrieves a single todo list by its ID
+// @ID get-list-by-id
+// @Produce json
+// @Param id path int true "List ID"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} listEnvelope
+// @Success 304 "Not modified"
+// @Header 200 {string} ETag "List version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /lists/{id} [get]
+// @x-api-v2 true
+func (h *Handler) getListByIdV2(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	list, err := h.requestServices(c).TodoList.GetById(userId, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if notModified(c, list.Version) {
+		return
+	}
+
+	c.JSON(http.StatusOK, listEnvelope{Data: list})
+}
+
+// @Summary Replace todo list
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Replaces every writable field of a todo list and returns the result. Omitted fields are reset; use PATCH for partial updates.
+// @ID update-list
+// @Accept json
+// @Produce json
+// @Param id path int true "List ID"
+// @Param input body todo.TodoList true "Full list"
+// @Param If-Match header string false "ETag the list must still have"
+// @Success 200 {object} listEnvelope
+// @Header 200 {string} ETag "List version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /lists/{id} [put]
+// @x-api-v2 true
+func (h *Handler) updateListV2(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input todo.TodoList
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	if err := h.servicesFor(c).TodoList.Replace(userId, id, input, version); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.respondList(c, userId, id)
+}
+
+// @Summary Patch todo list
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Partially updates a todo list with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) and returns the result. A merge patch may set description to null.
+// @ID patch-list
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "List ID"
+// @Param input body todo.UpdateListInput true "Merge patch, or an array of JSON Patch operations"
+// @Param If-Match header string false "ETag the list must still have"
+// @Success 200 {object} listEnvelope
+// @Header 200 {string} ETag "List version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 415 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /lists/{id} [patch]
+// @x-api-v2 true
+func (h *Handler) patchListV2(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.patchListBody(c, userId, id, version); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.respondList(c, userId, id)
+}
+
+// @Summary Delete todo list
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Moves a todo list to the trash. Archived lists are read-only and have to be unarchived first
+// @ID delete-list
+// @Param id path int true "List ID"
+// @Param If-Match header string false "ETag the list must still have"
+// @Success 204 "Deleted"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /lists/{id} [delete]
+// @x-api-v2 true
+func (h *Handler) deleteListV2(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.servicesFor(c).TodoList.Delete(userId, id, version); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondList answers with the list as it is after a change.
+func (h *Handler) respondList(c *gin.Context, userId, id int) {
+	list, err := h.requestServices(c).TodoList.GetById(userId, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header(etagHeader, etag(list.Version))
+	c.JSON(http.StatusOK, listEnvelope{Data: list})
+}
@@ -1,51 +1,161 @@
 package handler
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/service"
 	"github.com/gin-gonic/gin"
 )
 
 const (
 	authorizationHeader = "Authorization"
+	requestIdHeader = "X-Request-ID"
 	userCtx = "userId"
+	requestIdCtx = "requestId"
 )
 
+// requestIdPattern is what a client supplied request id must look like to
+// be propagated into logs, audit entries and response headers.
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// servicesKey is the request context key of the services a transactional
+// batch runs its sub-requests with.
+type servicesKey struct{}
+
+// requestId propagates the client supplied request id or generates a new one
+// when there is none or it is too long or contains unsafe characters.
+func (h *Handler) requestId(c *gin.Context){
+	id := c.GetHeader(requestIdHeader)
+	if !requestIdPattern.MatchString(id){
+		buf := make([]byte, 16)
+		rand.Read(buf)
+		id = hex.EncodeToString(buf)
+	}
+
+	c.Set(requestIdCtx, id)
+	c.Header(requestIdHeader, id)
+}
+
+// requestServices returns the services the request runs with: those sharing
+// the transaction of a transactional batch, or else the handler's own.
+func (h *Handler) requestServices(c *gin.Context) *service.Service{
+	if services, ok := c.Request.Context().Value(servicesKey{}).(*service.Service); ok{
+		return services
+	}
+	return h.services
+}
+
+// servicesFor returns the services acting on behalf of the request, which
+// audit the changes they make with its id and client IP.
+func (h *Handler) servicesFor(c *gin.Context) *service.Service{
+	return h.requestServices(c).WithRequest(todo.RequestInfo{Id: c.GetString(requestIdCtx), IP: c.ClientIP()})
+}
+
+// errorHandler renders the last error attached to the context as an
+// application/problem+json response.
+func (h *Handler) errorHandler(c *gin.Context){
+	c.Next()
+
+	last := c.Errors.Last()
+	if last == nil || c.Writer.Written(){
+		return
+	}
+
+	problem := newProblem(c, last.Err)
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
 func (h *Handler) userIdentity(c *gin.Context){
 	header := c.GetHeader(authorizationHeader)
 	if header == ""{
-		newErrorResponse(c, http.StatusUnauthorized, "empty auth header")
+		c.Error(unauthorized("empty auth header"))
+		c.Abort()
 		return
 	}
 
 	headerParts:= strings.Split(header, " ")
 	if len(headerParts) != 2{
-		newErrorResponse(c, http.StatusUnauthorized, "invalid auth header")
+		c.Error(unauthorized("invalid auth header"))
+		c.Abort()
 		return
 	}
 
-	userId, err := h.services.Authorization.ParseToken(headerParts[1])
+	userId, err := h.requestServices(c).Authorization.ParseToken(headerParts[1])
 	if err!=nil{
-		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		c.Error(unauthorized(err.Error()))
+		c.Abort()
 		return
 	}
 	c.Set(userCtx, userId)
 }
 
+// streamIdentity authenticates the event streams of a list. Besides the
+// Authorization header it accepts a ticket query param, since browsers
+// cannot set headers on EventSource and WebSocket requests.
+func (h *Handler) streamIdentity(c *gin.Context){
+	ticket := c.Query("ticket")
+	if ticket == ""{
+		h.userIdentity(c)
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil{
+		c.Error(badRequest("invalid id param"))
+		c.Abort()
+		return
+	}
+
+	userId, err := h.requestServices(c).Authorization.ParseEventTicket(ticket, listId)
+	if err != nil{
+		c.Error(unauthorized(err.Error()))
+		c.Abort()
+		return
+	}
+	c.Set(userCtx, userId)
+}
+
+// adminOnly lets only administrators through; it runs after userIdentity.
+func (h *Handler) adminOnly(c *gin.Context){
+	userId, err := getUserId(c)
+	if err != nil{
+		c.Abort()
+		return
+	}
+
+	isAdmin, err := h.requestServices(c).Authorization.IsAdmin(userId)
+	if err != nil{
+		c.Error(err)
+		c.Abort()
+		return
+	}
+	if !isAdmin{
+		c.Error(todo.ErrForbidden)
+		c.Abort()
+	}
+}
+
 func getUserId(c *gin.Context) (int, error){
 	id, ok := c.Get(userCtx)
 	if !ok{
-		newErrorResponse(c, http.StatusInternalServerError, "user id not found")
-		return 0, errors.New("user id not found")
+		err := errors.New("user id not found")
+		c.Error(err)
+		return 0, err
 	}
 
 	idInt, ok := id.(int)
 	if !ok{
-		newErrorResponse(c, http.StatusInternalServerError, "user id is of invalid type")
-		return 0, errors.New("user id not found")
+		err := errors.New("user id is of invalid type")
+		c.Error(err)
+		return 0, err
 	}
 
 	return idInt, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"strconv"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+)
+
+const cursorMacSize = 16
+
+// encodeCursor returns an opaque cursor pointing after the given id. The
+// id is signed together with the collection and filters of the request, so
+// clients can neither forge cursors into arbitrary positions nor carry them
+// over to another collection.
+func (h *Handler) encodeCursor(c *gin.Context, afterId int) string {
+	buf := make([]byte, 8, 8+cursorMacSize)
+	binary.BigEndian.PutUint64(buf, uint64(afterId))
+	buf = append(buf, h.cursorMac(c, buf[:8])...)
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func (h *Handler) decodeCursor(c *gin.Context, cursor string) (int, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(buf) != 8+cursorMacSize {
+		return 0, badRequest("invalid cursor param")
+	}
+
+	if !hmac.Equal(buf[8:], h.cursorMac(c, buf[:8])) {
+		return 0, badRequest("invalid cursor param")
+	}
+
+	return int(binary.BigEndian.Uint64(buf[:8])), nil
+}
+
+func (h *Handler) cursorMac(c *gin.Context, payload []byte) []byte {
+	mac := hmac.New(sha256.New, h.config.CursorKey)
+	mac.Write(payload)
+	mac.Write([]byte(cursorScope(c)))
+
+	return mac.Sum(nil)[:cursorMacSize]
+}
+
+// cursorScope identifies the collection a request pages through: its path
+// and every query param but the paging ones, in a stable order.
+func cursorScope(c *gin.Context) string {
+	query := c.Request.URL.Query()
+	query.Del("cursor")
+	query.Del("limit")
+
+	return c.Request.URL.Path + "?" + query.Encode()
+}
+
+// getPage reads the limit and cursor query params.
+func (h *Handler) getPage(c *gin.Context) (todo.Page, error) {
+	var page todo.Page
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return page, badRequest("invalid limit param")
+		}
+		page.Limit = limit
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		afterId, err := h.decodeCursor(c, value)
+		if err != nil {
+			return page, err
+		}
+		page.AfterId = afterId
+	}
+
+	return page, nil
+}
@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// listDocument is the writable part of a list that JSON Patch operates on.
+type listDocument struct {
+	Title       string  `json:"title"`
+	Description *string `json:"description"`
+}
+
+// itemDocument is the writable part of an item that JSON Patch operates on.
+type itemDocument struct {
+	Title       string     `json:"title"`
+	Description *string    `json:"description"`
+	Done        bool       `json:"done"`
+	DueDate     *time.Time `json:"due_date"`
+	Priority    int        `json:"priority"`
+	Tags        []string   `json:"tags"`
+	Recurrence  string     `json:"recurrence"`
+}
+
+func unsupportedPatchType() error {
+	return &requestError{
+		status: http.StatusUnsupportedMediaType,
+		code:   codeUnsupportedMediaType,
+		detail: "patch must be " + mergePatchContentType + " or " + jsonPatchContentType,
+	}
+}
+
+// decodeMergePatch decodes an RFC 7396 merge patch into input. Only the
+// nullable fields may be removed with null.
+func decodeMergePatch(body []byte, input interface{}, nullable ...string) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return badRequest("merge patch must be a JSON object")
+	}
+
+	invalid := make([]fieldError, 0)
+	for name, value := range fields {
+		if string(bytes.TrimSpace(value)) == "null" && !slices.Contains(nullable, name) {
+			invalid = append(invalid, fieldError{Field: name, Message: "cannot be null"})
+		}
+	}
+	if len(invalid) > 0 {
+		return &requestError{
+			status: http.StatusUnprocessableEntity,
+			code:   codeValidationFailed,
+			detail: "merge patch is invalid",
+			fields: invalid,
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(input); err != nil {
+		return badRequest("invalid merge patch: " + err.Error())
+	}
+
+	return nil
+}
+
+// applyJSONPatch applies an RFC 6902 patch to document and returns the
+// result. The result is decoded into a fresh document, so that members the
+// patch removed come out empty instead of keeping their old values.
+func applyJSONPatch[T any](body []byte, document T) (T, error) {
+	var result T
+
+	patch, err := jsonpatch.DecodePatch(body)
+	if err != nil {
+		return result, badRequest("invalid JSON patch: " + err.Error())
+	}
+
+	original, err := json.Marshal(document)
+	if err != nil {
+		return result, err
+	}
+
+	patched, err := patch.Apply(original)
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return result, &requestError{status: http.StatusConflict, code: codeConflict, detail: err.Error()}
+	}
+	if err != nil {
+		return result, &requestError{status: http.StatusUnprocessableEntity, code: codeValidationFailed, detail: "JSON patch cannot be applied: " + err.Error()}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return result, &requestError{status: http.StatusUnprocessableEntity, code: codeValidationFailed, detail: "patched document is invalid: " + err.Error()}
+	}
+
+	return result, nil
+}
+
+// @Summary Patch todo list
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Partially updates a todo list with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902). A merge patch may set description to null.
+// @ID patch-list
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "List ID"
+// @Param input body todo.UpdateListInput true "Merge patch, or an array of JSON Patch operations"
+// @Param If-Match header string false "ETag the list must still have"
+// @Success 200 {object} statusResponse
+// @Header 200 {string} ETag "List version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 415 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/lists/{id} [patch]
+// @x-api-v1 true
+func (h *Handler) patchList(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.patchListBody(c, userId, id, version); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.changedListETag(c, userId, id)
+	c.JSON(http.StatusOK, statusResponse{"Ok"})
+}
+
+// @Summary Patch todo list item
+// @Security ApiKeyAuth
+// @Tags items
+// @Description Partially updates a todo list item with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902). A merge patch may set description and due_date to null.
+// @ID patch-item
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "Item ID"
+// @Param input body todo.UpdateItemInput true "Merge patch, or an array of JSON Patch operations"
+// @Param If-Match header string false "ETag the item must still have"
+// @Success 200 {object} statusResponse
+// @Header 200 {string} ETag "Item version"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 415 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/items/{id} [patch]
+// @x-api-v1 true
+func (h *Handler) patchItem(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.patchItemBody(c, userId, id, version); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.changedItemETag(c, userId, id)
+	c.JSON(http.StatusOK, statusResponse{"Ok"})
+}
+
+// patchListBody applies the patch in the request body to the list in the
+// format named by its Content-Type.
+func (h *Handler) patchListBody(c *gin.Context, userId, id, version int) error {
+	body, err := c.GetRawData()
+	if err != nil {
+		return badRequest("failed to read request body")
+	}
+
+	switch c.ContentType() {
+	case mergePatchContentType, "application/json":
+		var input todo.UpdateListInput
+		if err := decodeMergePatch(body, &input, "description"); err != nil {
+			return err
+		}
+
+		return h.servicesFor(c).TodoList.Update(userId, id, input, version)
+	case jsonPatchContentType:
+		list, err := h.requestServices(c).TodoList.GetById(userId, id)
+		if err != nil {
+			return err
+		}
+
+		document, err := applyJSONPatch(body, listDocument{Title: list.Title, Description: list.Description})
+		if err != nil {
+			return err
+		}
+
+		if version == 0 {
+			version = list.Version
+		}
+		return h.servicesFor(c).TodoList.Replace(userId, id, todo.TodoList{
+			Title:       document.Title,
+			Description: document.Description,
+		}, version)
+	default:
+		return unsupportedPatchType()
+	}
+}
+
+// patchItemBody applies the patch in the request body to the item in the
+// format named by its Content-Type.
+func (h *Handler) patchItemBody(c *gin.Context, userId, id, version int) error {
+	body, err := c.GetRawData()
+	if err != nil {
+		return badRequest("failed to read request body")
+	}
+
+	switch c.ContentType() {
+	case mergePatchContentType, "application/json":
+		var input todo.UpdateItemInput
+		if err := decodeMergePatch(body, &input, "description", "due_date"); err != nil {
+			return err
+		}
+
+		return h.servicesFor(c).TodoItem.Update(userId, id, input, version)
+	case jsonPatchContentType:
+		item, err := h.requestServices(c).TodoItem.GetById(userId, id)
+		if err != nil {
+			return err
+		}
+
+		document, err := applyJSONPatch(body, itemDocument{
+			Title:       item.Title,
+			Description: item.Description,
+			Done:        item.Done,
+			DueDate:     item.DueDate,
+			Priority:    item.Priority,
+			Tags:        item.Tags,
+			Recurrence:  item.Recurrence,
+		})
+		if err != nil {
+			return err
+		}
+
+		if version == 0 {
+			version = item.Version
+		}
+		return h.servicesFor(c).TodoItem.Replace(userId, id, todo.TodoItem{
+			Title:       document.Title,
+			Description: document.Description,
+			Done:        document.Done,
+			DueDate:     document.DueDate,
+			Priority:    document.Priority,
+			Tags:        document.Tags,
+			Recurrence:  document.Recurrence,
+		}, version)
+	default:
+		return unsupportedPatchType()
+	}
+}
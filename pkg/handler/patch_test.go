@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/service"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeItemRepo keeps a single item in memory.
+type fakeItemRepo struct {
+	repository.TodoItem
+
+	item todo.TodoItem
+}
+
+func (r *fakeItemRepo) GetById(userId, itemId int) (todo.TodoItem, error) {
+	if itemId != r.item.Id {
+		return todo.TodoItem{}, todo.ErrNotFound
+	}
+	return r.item, nil
+}
+
+func (r *fakeItemRepo) GetForUpdate(userId, itemId int) (todo.TodoItem, error) {
+	return r.GetById(userId, itemId)
+}
+
+func (r *fakeItemRepo) GetListId(userId, itemId int) (int, error) {
+	if itemId != r.item.Id {
+		return 0, todo.ErrNotFound
+	}
+	return r.item.ListId, nil
+}
+
+func (r *fakeItemRepo) Update(userId, itemId int, input todo.UpdateItemInput, version int) error {
+	if version != 0 && version != r.item.Version {
+		return todo.ErrPreconditionFailed
+	}
+
+	if input.Title != nil {
+		r.item.Title = *input.Title
+	}
+	if input.Description.Set {
+		r.item.Description = input.Description.Value
+	}
+	if input.Done != nil {
+		r.item.Done = *input.Done
+	}
+	if input.DueDate.Set {
+		r.item.DueDate = input.DueDate.Value
+	}
+	if input.Priority != nil {
+		r.item.Priority = *input.Priority
+	}
+	if input.Tags != nil {
+		r.item.Tags = *input.Tags
+	}
+	if input.Recurrence != nil {
+		r.item.Recurrence = *input.Recurrence
+	}
+	r.item.Version++
+
+	return nil
+}
+
+type fakeListRepo struct {
+	repository.TodoList
+}
+
+func (r fakeListRepo) GetById(userId, listId int) (todo.TodoList, error) {
+	return todo.TodoList{Id: listId, Title: "Chores"}, nil
+}
+
+// The discard repositories drop the events, audit entries and revisions
+// a change records.
+type discardOutbox struct{ repository.Outbox }
+
+func (discardOutbox) Publish(event todo.Event) error { return nil }
+
+type discardAudit struct{ repository.Audit }
+
+func (discardAudit) Create(entry todo.AuditEntry) error { return nil }
+
+type discardRevisions struct{ repository.ItemRevision }
+
+func (discardRevisions) Create(revision todo.ItemRevision) error { return nil }
+
+func newPatchTestRouter(items *fakeItemRepo) *gin.Engine {
+	repos := &repository.Repository{
+		TodoItem:     items,
+		TodoList:     fakeListRepo{},
+		Outbox:       discardOutbox{},
+		Audit:        discardAudit{},
+		ItemRevision: discardRevisions{},
+	}
+	h := NewHandler(service.NewService(repos), Config{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(h.errorHandler)
+	router.PATCH("/api/items/:id", func(c *gin.Context) { c.Set(userCtx, 1) }, h.patchItem)
+
+	return router
+}
+
+func TestPatchItemJSONPatch(t *testing.T) {
+	description := "two litres"
+	due := time.Date(2024, time.March, 14, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		patch      string
+		wantStatus int
+		check      func(t *testing.T, item todo.TodoItem)
+	}{
+		{
+			name:       "replace",
+			patch:      `[{"op":"replace","path":"/title","value":"Buy oat milk"},{"op":"replace","path":"/priority","value":3}]`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, item todo.TodoItem) {
+				if item.Title != "Buy oat milk" || item.Priority != todo.PriorityHigh {
+					t.Errorf("title, priority = %q, %d, want %q, %d", item.Title, item.Priority, "Buy oat milk", todo.PriorityHigh)
+				}
+				if item.Description == nil || *item.Description != description {
+					t.Errorf("description = %v, want it kept", item.Description)
+				}
+			},
+		},
+		{
+			name:       "remove clears the member",
+			patch:      `[{"op":"remove","path":"/description"},{"op":"remove","path":"/due_date"},{"op":"remove","path":"/tags"}]`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, item todo.TodoItem) {
+				if item.Description != nil {
+					t.Errorf("description = %q, want null", *item.Description)
+				}
+				if item.DueDate != nil {
+					t.Errorf("due_date = %v, want null", *item.DueDate)
+				}
+				if len(item.Tags) != 0 {
+					t.Errorf("tags = %v, want none", item.Tags)
+				}
+				if item.Title != "Buy milk" {
+					t.Errorf("title = %q, want it kept", item.Title)
+				}
+			},
+		},
+		{
+			name:       "remove title is invalid",
+			patch:      `[{"op":"remove","path":"/title"}]`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "passing test applies the patch",
+			patch:      `[{"op":"test","path":"/title","value":"Buy milk"},{"op":"replace","path":"/done","value":true}]`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, item todo.TodoItem) {
+				if !item.Done {
+					t.Error("done = false, want true")
+				}
+			},
+		},
+		{
+			name:       "failing test conflicts",
+			patch:      `[{"op":"test","path":"/title","value":"Buy bread"},{"op":"replace","path":"/done","value":true}]`,
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := &fakeItemRepo{item: todo.TodoItem{
+				Id:          1,
+				ListId:      2,
+				Title:       "Buy milk",
+				Description: &description,
+				DueDate:     &due,
+				Tags:        todo.Tags{"shop"},
+				Version:     4,
+			}}
+			before := items.item
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/items/1", strings.NewReader(tt.patch))
+			req.Header.Set("Content-Type", jsonPatchContentType)
+			w := httptest.NewRecorder()
+			newPatchTestRouter(items).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.check == nil {
+				if items.item.Version != before.Version {
+					t.Errorf("item changed by a rejected patch: %+v", items.item)
+				}
+				return
+			}
+			tt.check(t, items.item)
+		})
+	}
+}
+
+func TestPatchItemIfMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		ifMatch    string
+		wantStatus int
+		wantETag   string
+	}{
+		{
+			name:       "current version",
+			ifMatch:    `"4"`,
+			wantStatus: http.StatusOK,
+			wantETag:   `"5"`,
+		},
+		{
+			name:       "stale version",
+			ifMatch:    `"3"`,
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "weak tag never matches",
+			ifMatch:    `W/"4"`,
+			wantStatus: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := &fakeItemRepo{item: todo.TodoItem{Id: 1, ListId: 2, Title: "Buy milk", Version: 4}}
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/items/1", strings.NewReader(`{"done":true}`))
+			req.Header.Set("Content-Type", mergePatchContentType)
+			req.Header.Set(ifMatchHeader, tt.ifMatch)
+			w := httptest.NewRecorder()
+			newPatchTestRouter(items).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get(etagHeader); got != tt.wantETag {
+				t.Errorf("%s = %q, want %q", etagHeader, got, tt.wantETag)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary Quick add
+// @Security ApiKeyAuth
+// @Tags items
+// @Description Creates an item from a single line of text such as "Pay rent tomorrow 9am #home !high every month @Bills" and returns what was understood
+// @ID quick-add
+// @Accept json
+// @Produce json
+// @Param input body todo.QuickAddInput true "Text to parse"
+// @Success 201 {object} todo.TodoItem
+// @Header 201 {string} Location "Path of the created item"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/quick-add [post]
+// @x-api-v1 true
+func (h *Handler) quickAdd(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	var input todo.QuickAddInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	item, err := h.servicesFor(c).QuickAdd.Create(userId, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	created(c, fmt.Sprintf("/api/items/%d", item.Id), item)
+}
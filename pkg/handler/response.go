@@ -1,20 +1,151 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/MyNameIsWhaaat/todo-app"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/sirupsen/logrus"
 )
 
-type errorResponse struct{
+const problemContentType = "application/problem+json"
+
+// Stable machine-readable error codes returned in problemResponse.Code.
+const (
+	codeBadRequest           = "bad_request"
+	codeUnauthorized         = "unauthorized"
+	codeForbidden            = "forbidden"
+	codeNotFound             = "not_found"
+	codeConflict             = "conflict"
+	codePrecondition         = "precondition_failed"
+	codeBulkFailed           = "bulk_failed"
+	codeFailedDependency     = "failed_dependency"
+	codeUnsupportedMediaType = "unsupported_media_type"
+	codeValidationFailed     = "validation_failed"
+	codeInternalError        = "internal_error"
+)
+
+// problemResponse is an RFC 7807 problem details object.
+type problemResponse struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestId string       `json:"request_id,omitempty"`
+	Errors    []fieldError `json:"errors,omitempty"`
+	// Results reports every item of a bulk operation that was rolled back.
+	Results []todo.BulkItemResult `json:"results,omitempty"`
+}
+
+type fieldError struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
 }
 
-type statusResponse struct{
+type statusResponse struct {
 	Status string `json:"status"`
 }
 
-func newErrorResponse(c *gin.Context, statusCode int, message string){
-	logrus.Error(message)
-	c.AbortWithStatusJSON(statusCode, errorResponse{message})
+// requestError is raised by handlers and middleware for problems with the
+// request itself, before it reaches the service layer.
+type requestError struct {
+	status int
+	code   string
+	detail string
+	fields []fieldError
 }
 
+func (e *requestError) Error() string {
+	return e.detail
+}
+
+// created responds 201 Created with the new resource and the path it can be
+// fetched from.
+func created(c *gin.Context, location string, resource interface{}) {
+	c.Header("Location", location)
+	c.JSON(http.StatusCreated, resource)
+}
+
+func badRequest(detail string) error {
+	return &requestError{status: http.StatusBadRequest, code: codeBadRequest, detail: detail}
+}
+
+func unauthorized(detail string) error {
+	return &requestError{status: http.StatusUnauthorized, code: codeUnauthorized, detail: detail}
+}
+
+// bindingError turns a request body binding failure into a request error,
+// reporting validator failures field by field.
+func bindingError(err error) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return badRequest(err.Error())
+	}
+
+	fields := make([]fieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		message := "must satisfy " + fe.Tag()
+		if fe.Tag() == "required" {
+			message = "is required"
+		}
+		fields = append(fields, fieldError{Field: fe.Field(), Message: message})
+	}
+
+	return &requestError{
+		status: http.StatusUnprocessableEntity,
+		code:   codeValidationFailed,
+		detail: "request body is invalid",
+		fields: fields,
+	}
+}
+
+// newProblem maps an error raised while handling c onto a problem response.
+// Errors that are not recognised are reported as 500 without exposing
+// their text to the client.
+func newProblem(c *gin.Context, err error) problemResponse {
+	p := problemResponse{
+		Type:      "about:blank",
+		Instance:  c.Request.URL.Path,
+		RequestId: c.GetString(requestIdCtx),
+		Detail:    err.Error(),
+	}
+
+	var reqErr *requestError
+	var verr *todo.ValidationError
+	var bulkErr *todo.BulkError
+	switch {
+	case errors.As(err, &reqErr):
+		p.Status, p.Code, p.Errors = reqErr.status, reqErr.code, reqErr.fields
+	case errors.As(err, &verr):
+		p.Status, p.Code = http.StatusUnprocessableEntity, codeValidationFailed
+		p.Errors = make([]fieldError, 0, len(verr.Fields))
+		for field, message := range verr.Fields {
+			p.Errors = append(p.Errors, fieldError{Field: field, Message: message})
+		}
+		sort.Slice(p.Errors, func(i, j int) bool { return p.Errors[i].Field < p.Errors[j].Field })
+	case errors.As(err, &bulkErr):
+		p.Status, p.Code, p.Results = http.StatusConflict, codeBulkFailed, bulkErr.Results
+	case errors.Is(err, todo.ErrValidation):
+		p.Status, p.Code = http.StatusUnprocessableEntity, codeValidationFailed
+	case errors.Is(err, todo.ErrNotFound):
+		p.Status, p.Code = http.StatusNotFound, codeNotFound
+	case errors.Is(err, todo.ErrForbidden):
+		p.Status, p.Code = http.StatusForbidden, codeForbidden
+	case errors.Is(err, todo.ErrPreconditionFailed):
+		p.Status, p.Code = http.StatusPreconditionFailed, codePrecondition
+	case errors.Is(err, todo.ErrConflict):
+		p.Status, p.Code = http.StatusConflict, codeConflict
+	default:
+		logrus.WithField("request_id", p.RequestId).Error(err.Error())
+		p.Status, p.Code = http.StatusInternalServerError, codeInternalError
+		p.Detail = ""
+	}
+	p.Title = http.StatusText(p.Status)
+
+	return p
+}
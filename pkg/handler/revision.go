@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type getItemRevisionsResponse struct {
+	Data       []todo.ItemRevision `json:"data"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// @Summary Get item revisions
+// @Security ApiKeyAuth
+// @Tags items
+// @Description Retrieves the earlier versions of a todo list item, oldest first. Every change to the item keeps the version it replaced
+// @ID get-item-revisions
+// @Accept json
+// @Produce json
+// @Param id path int true "Item ID"
+// @Param limit query int false "Page size, 50 by default and at most 100"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} getItemRevisionsResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/items/{id}/revisions [get]
+// @x-api-v1 true
+func (h *Handler) getItemRevisions(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	page, err := h.getPage(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	revisions, more, err := h.requestServices(c).TodoItem.GetRevisions(userId, id, page)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := getItemRevisionsResponse{
+		Data: revisions,
+	}
+	if more {
+		response.NextCursor = h.encodeCursor(c, revisions[len(revisions)-1].Revision)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Restore item revision
+// @Security ApiKeyAuth
+// @Tags items
+// @Description Reverts the title, description, done, due date, priority, tags and recurrence of a todo list item to those of an earlier revision. The version replaced by the restore is kept as a revision
+// @ID restore-item-revision
+// @Accept json
+// @Produce json
+// @Param id path int true "Item ID"
+// @Param rev path int true "Revision"
+// @Param If-Match header string false "ETag the item must still have"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 409 {object} problemResponse
+// @Failure 412 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/items/{id}/revisions/{rev}/restore [post]
+// @x-api-v1 true
+func (h *Handler) restoreItemRevision(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	revision, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.Error(badRequest("invalid rev param"))
+		return
+	}
+
+	version, err := getIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.servicesFor(c).TodoItem.RestoreRevision(userId, id, revision, version); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{"Ok"})
+}
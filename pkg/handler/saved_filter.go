@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary Create saved filter
+// @Security ApiKeyAuth
+// @Tags filters
+// @Description Saves a filter definition that can be evaluated as a smart list
+// @ID create-filter
+// @Accept json
+// @Produce json
+// @Param input body todo.SavedFilter true "Filter info"
+// @Success 200 {integer} integer
+// @Failure 400 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/filters [post]
+// @x-api-v1 true
+func (h *Handler) createFilter(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	var input todo.SavedFilter
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	id, err := h.requestServices(c).SavedFilter.Create(userId, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+type getAllFiltersResponse struct {
+	Data []todo.SavedFilter `json:"data"`
+}
+
+// @Summary Get all saved filters
+// @Security ApiKeyAuth
+// @Tags filters
+// @Description Retrieves all saved filters of the authenticated user
+// @ID get-filters
+// @Accept json
+// @Produce json
+// @Success 200 {object} getAllFiltersResponse
+// @Failure 400 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/filters [get]
+// @x-api-v1 true
+func (h *Handler) getAllFilters(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	filters, err := h.requestServices(c).SavedFilter.GetAll(userId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getAllFiltersResponse{
+		Data: filters,
+	})
+}
+
+// @Summary Get saved filter by ID
+// @Security ApiKeyAuth
+// @Tags filters
+// @Description Retrieves a single saved filter by its ID
+// @ID get-filter-by-id
+// @Accept json
+// @Produce json
+// @Param id path int true "Filter ID"
+// @Success 200 {object} todo.SavedFilter
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/filters/{id} [get]
+// @x-api-v1 true
+func (h *Handler) getFilterById(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	filter, err := h.requestServices(c).SavedFilter.GetById(userId, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, filter)
+}
+
+// @Summary Update saved filter
+// @Security ApiKeyAuth
+// @Tags filters
+// @Description Updates a saved filter by its ID
+// @ID update-filter
+// @Accept json
+// @Produce json
+// @Param id path int true "Filter ID"
+// @Param input body todo.UpdateSavedFilterInput true "Update params"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/filters/{id} [put]
+// @x-api-v1 true
+func (h *Handler) updateFilter(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	var input todo.UpdateSavedFilterInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	if err := h.requestServices(c).SavedFilter.Update(userId, id, input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary Delete saved filter
+// @Security ApiKeyAuth
+// @Tags filters
+// @Description Deletes a saved filter by its ID
+// @ID delete-filter
+// @Accept json
+// @Produce json
+// @Param id path int true "Filter ID"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/filters/{id} [delete]
+// @x-api-v1 true
+func (h *Handler) deleteFilter(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	if err := h.requestServices(c).SavedFilter.Delete(userId, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary Get saved filter items
+// @Security ApiKeyAuth
+// @Tags filters
+// @Description Evaluates a saved filter against all active lists of the user
+// @ID get-filter-items
+// @Accept json
+// @Produce json
+// @Param id path int true "Filter ID"
+// @Param limit query int false "Page size, 50 by default and at most 100"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} getAllItemsResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/filters/{id}/items [get]
+// @x-api-v1 true
+func (h *Handler) getFilterItems(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	page, err := h.getPage(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	items, more, err := h.requestServices(c).SavedFilter.GetItems(userId, id, page)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := getAllItemsResponse{
+		Data: items,
+	}
+	if more {
+		response.NextCursor = h.encodeCursor(c, items[len(items)-1].Id)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type searchResponse struct {
+	Data []todo.SearchResult `json:"data"`
+}
+
+// @Summary Search
+// @Security ApiKeyAuth
+// @Tags search
+// @Description Full-text search over titles and descriptions of all lists and items of the user, best matches first. Results are not paged
+// @ID search
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query, supports quoted phrases, OR and -exclusions"
+// @Param limit query int false "Number of results, 50 by default and at most 100"
+// @Success 200 {object} searchResponse
+// @Failure 400 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/search [get]
+// @x-api-v1 true
+func (h *Handler) search(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	// Results are ranked, not keyed by id, so there is no next page to
+	// point a cursor at.
+	if c.Query("cursor") != "" {
+		c.Error(badRequest("search results are not paged, use limit instead of cursor"))
+		return
+	}
+
+	page, err := h.getPage(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	results, err := h.requestServices(c).Search.Search(userId, c.Query("q"), page)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, searchResponse{
+		Data: results,
+	})
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary Get settings
+// @Security ApiKeyAuth
+// @Tags settings
+// @Description Retrieves settings of the authenticated user
+// @ID get-settings
+// @Accept json
+// @Produce json
+// @Success 200 {object} todo.UserSettings
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/settings [get]
+// @x-api-v1 true
+func (h *Handler) getSettings(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	settings, err := h.requestServices(c).Settings.Get(userId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// @Summary Update settings
+// @Security ApiKeyAuth
+// @Tags settings
+// @Description Updates settings of the authenticated user
+// @ID update-settings
+// @Accept json
+// @Produce json
+// @Param input body todo.UpdateSettingsInput true "Update params"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/settings [put]
+// @x-api-v1 true
+func (h *Handler) updateSettings(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	var input todo.UpdateSettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	if err := h.requestServices(c).Settings.Update(userId, input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
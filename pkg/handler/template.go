@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary Save todo list as template
+// @Security ApiKeyAuth
+// @Tags templates
+// @Description Saves a todo list and its items as a reusable template
+// @ID save-list-as-template
+// @Accept json
+// @Produce json
+// @Param id path int true "List ID"
+// @Param input body todo.SaveTemplateInput false "Template options"
+// @Success 201 {object} todo.ListTemplate
+// @Header 201 {string} Location "Path of the created template"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/lists/{id}/template [post]
+// @x-api-v1 true
+func (h *Handler) saveListAsTemplate(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid list id param"))
+		return
+	}
+
+	var input todo.SaveTemplateInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(bindingError(err))
+			return
+		}
+	}
+
+	services := h.requestServices(c)
+	id, err := services.ListTemplate.CreateFromList(userId, listId, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	template, err := services.ListTemplate.GetById(userId, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	created(c, fmt.Sprintf("/api/templates/%d", id), template)
+}
+
+type getAllTemplatesResponse struct {
+	Data []todo.ListTemplate `json:"data"`
+}
+
+// @Summary Get all templates
+// @Security ApiKeyAuth
+// @Tags templates
+// @Description Retrieves all list templates of the authenticated user
+// @ID get-templates
+// @Accept json
+// @Produce json
+// @Success 200 {object} getAllTemplatesResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/templates [get]
+// @x-api-v1 true
+func (h *Handler) getAllTemplates(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	templates, err := h.requestServices(c).ListTemplate.GetAll(userId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getAllTemplatesResponse{
+		Data: templates,
+	})
+}
+
+// @Summary Get template by ID
+// @Security ApiKeyAuth
+// @Tags templates
+// @Description Retrieves a single template with its items
+// @ID get-template-by-id
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} todo.ListTemplate
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/templates/{id} [get]
+// @x-api-v1 true
+func (h *Handler) getTemplateById(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	template, err := h.requestServices(c).ListTemplate.GetById(userId, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// @Summary Delete template
+// @Security ApiKeyAuth
+// @Tags templates
+// @Description Deletes a template by its ID
+// @ID delete-template
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/templates/{id} [delete]
+// @x-api-v1 true
+func (h *Handler) deleteTemplate(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	if err := h.requestServices(c).ListTemplate.Delete(userId, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{"Ok"})
+}
+
+// @Summary Instantiate template
+// @Security ApiKeyAuth
+// @Tags templates
+// @Description Creates a new todo list from a template, substituting variables in item titles
+// @ID instantiate-template
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param input body todo.InstantiateTemplateInput false "Instantiate options"
+// @Success 201 {object} todo.TodoList
+// @Header 201 {string} Location "Path of the created list"
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/templates/{id}/instantiate [post]
+// @x-api-v1 true
+func (h *Handler) instantiateTemplate(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	var input todo.InstantiateTemplateInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(bindingError(err))
+			return
+		}
+	}
+
+	services := h.servicesFor(c)
+	listId, err := services.ListTemplate.Instantiate(userId, id, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	list, err := services.TodoList.GetById(userId, listId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	created(c, fmt.Sprintf("/api/lists/%d", listId), list)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary Get trash
+// @Security ApiKeyAuth
+// @Tags trash
+// @Description Retrieves deleted lists and items that can still be restored. Items of deleted lists come back with their list and are not listed.
+// @ID get-trash
+// @Accept json
+// @Produce json
+// @Success 200 {object} todo.Trash
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/trash [get]
+// @x-api-v1 true
+func (h *Handler) getTrash(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	trash, err := h.requestServices(c).Trash.GetAll(userId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
+
+// @Summary Restore from trash
+// @Security ApiKeyAuth
+// @Tags trash
+// @Description Restores a deleted list or item
+// @ID restore-from-trash
+// @Accept json
+// @Produce json
+// @Param type path string true "Entity type" Enums(lists, items)
+// @Param id path int true "Entity ID"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/trash/{type}/{id}/restore [post]
+// @x-api-v1 true
+func (h *Handler) restoreFromTrash(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	switch c.Param("type") {
+	case "lists":
+		err = h.servicesFor(c).Trash.RestoreList(userId, id)
+	case "items":
+		err = h.servicesFor(c).Trash.RestoreItem(userId, id)
+	default:
+		c.Error(badRequest("invalid type param"))
+		return
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{"Ok"})
+}
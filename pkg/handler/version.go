@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"golang.org/x/net/webdav"
+
+	_ "github.com/MyNameIsWhaaat/todo-app/docs/v2"
+)
+
+// @title Todo App API
+// @version 2.0
+// @description API Server for TodoList Application. Every response body is wrapped in a data envelope, creates answer 201 with a Location, updates return the changed resource and deletes answer 204.
+
+// @host localhost:8000
+// @BasePath /api/v2
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+
+const (
+	deprecationHeader = "Deprecation"
+	sunsetHeader      = "Sunset"
+	linkHeader        = "Link"
+
+	apiV2Prefix = "/api/v2"
+)
+
+// VersionPolicy announces the retirement of an API version. Zero times are
+// not announced.
+type VersionPolicy struct {
+	// DeprecatedAt is when the version was, or will be, deprecated.
+	DeprecatedAt time.Time
+	// SunsetAt is when the version stops being served.
+	SunsetAt time.Time
+}
+
+// Config configures the routes served by a Handler.
+type Config struct {
+	V1 VersionPolicy
+	// CursorKey signs the paging cursors handed out to clients. Replicas
+	// serving the same clients have to share it.
+	CursorKey []byte
+	// TrustedProxies are the addresses or CIDRs of the reverse proxies
+	// whose X-Forwarded-For and X-Real-IP headers are believed. The client
+	// IP of requests from any other peer is their remote address.
+	TrustedProxies []string
+}
+
+// listEnvelope is the v2 response body carrying a single list.
+type listEnvelope struct {
+	Data todo.TodoList `json:"data"`
+}
+
+// itemEnvelope is the v2 response body carrying a single item.
+type itemEnvelope struct {
+	Data todo.TodoItem `json:"data"`
+}
+
+// deprecated returns middleware announcing the policy with the Deprecation
+// (RFC 9745) and Sunset (RFC 8594) headers and linking to the successor
+// version.
+func deprecated(policy VersionPolicy, successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !policy.DeprecatedAt.IsZero() {
+			c.Header(deprecationHeader, fmt.Sprintf("@%d", policy.DeprecatedAt.Unix()))
+		}
+		if !policy.SunsetAt.IsZero() {
+			c.Header(sunsetHeader, policy.SunsetAt.UTC().Format(http.TimeFormat))
+		}
+		if !policy.DeprecatedAt.IsZero() || !policy.SunsetAt.IsZero() {
+			c.Header(linkHeader, fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+		}
+
+		c.Next()
+	}
+}
+
+// swaggerHandler serves the v1 document under /swagger/ and the v2 one under
+// /swagger/v2/. Each needs its own file handler since the handler remembers
+// the prefix it was first served under.
+func swaggerHandler() gin.HandlerFunc {
+	v1 := ginSwagger.WrapHandler(swaggerFiles.Handler)
+	v2 := ginSwagger.WrapHandler(&webdav.Handler{
+		FileSystem: swaggerFiles.FS,
+		LockSystem: webdav.NewMemLS(),
+	}, ginSwagger.InstanceName("v2"))
+
+	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Param("any"), "/v2/") {
+			v2(c)
+			return
+		}
+		v1(c)
+	}
+}
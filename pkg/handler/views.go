@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type viewResponse struct {
+	Data []todo.ListItems `json:"data"`
+}
+
+// @Summary Today
+// @Security ApiKeyAuth
+// @Tags views
+// @Description Open items due today or earlier across all active lists, grouped by list, in the user's time zone
+// @ID view-today
+// @Produce json
+// @Success 200 {object} viewResponse
+// @Failure 401 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/views/today [get]
+// @x-api-v1 true
+func (h *Handler) getTodayView(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	groups, err := h.requestServices(c).Views.Today(userId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, viewResponse{
+		Data: groups,
+	})
+}
+
+// @Summary Upcoming
+// @Security ApiKeyAuth
+// @Tags views
+// @Description Open items due from tomorrow through the next days across all active lists, grouped by list, in the user's time zone
+// @ID view-upcoming
+// @Produce json
+// @Param days query int false "Number of days to look ahead, 7 by default and at most 90"
+// @Success 200 {object} viewResponse
+// @Failure 400 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/views/upcoming [get]
+// @x-api-v1 true
+func (h *Handler) getUpcomingView(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	days := todo.DefaultUpcomingDays
+	if value, ok := c.GetQuery("days"); ok {
+		days, err = strconv.Atoi(value)
+		if err != nil {
+			c.Error(badRequest("invalid days param"))
+			return
+		}
+	}
+
+	groups, err := h.requestServices(c).Views.Upcoming(userId, days)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, viewResponse{
+		Data: groups,
+	})
+}
+
+// @Summary Overdue
+// @Security ApiKeyAuth
+// @Tags views
+// @Description Open items due before today across all active lists, grouped by list, in the user's time zone
+// @ID view-overdue
+// @Produce json
+// @Success 200 {object} viewResponse
+// @Failure 401 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/views/overdue [get]
+// @x-api-v1 true
+func (h *Handler) getOverdueView(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	groups, err := h.requestServices(c).Views.Overdue(userId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, viewResponse{
+		Data: groups,
+	})
+}
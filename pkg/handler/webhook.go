@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary Create webhook
+// @Security ApiKeyAuth
+// @Tags webhooks
+// @Description Subscribes a URL to events of the user's lists. Every delivery is a POST of the event signed in the X-Webhook-Signature header as "sha256=" followed by the hex HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>" keyed with the secret. A delivery may be repeated; the id in the body is unique per event
+// @ID create-webhook
+// @Accept json
+// @Produce json
+// @Param input body todo.WebhookInput true "Webhook info"
+// @Success 201 {object} todo.Webhook
+// @Failure 400 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/webhooks [post]
+// @x-api-v1 true
+func (h *Handler) createWebhook(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	var input todo.WebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	id, err := h.requestServices(c).Webhook.Create(userId, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	webhook, err := h.requestServices(c).Webhook.GetById(userId, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	created(c, fmt.Sprintf("/api/webhooks/%d", id), webhook)
+}
+
+type getAllWebhooksResponse struct {
+	Data []todo.Webhook `json:"data"`
+}
+
+// @Summary Get all webhooks
+// @Security ApiKeyAuth
+// @Tags webhooks
+// @Description Retrieves all webhooks of the authenticated user
+// @ID get-webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {object} getAllWebhooksResponse
+// @Failure 400 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/webhooks [get]
+// @x-api-v1 true
+func (h *Handler) getAllWebhooks(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	webhooks, err := h.requestServices(c).Webhook.GetAll(userId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getAllWebhooksResponse{
+		Data: webhooks,
+	})
+}
+
+// @Summary Get webhook by ID
+// @Security ApiKeyAuth
+// @Tags webhooks
+// @Description Retrieves a single webhook by its ID
+// @ID get-webhook-by-id
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} todo.Webhook
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/webhooks/{id} [get]
+// @x-api-v1 true
+func (h *Handler) getWebhookById(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	webhook, err := h.requestServices(c).Webhook.GetById(userId, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// @Summary Update webhook
+// @Security ApiKeyAuth
+// @Tags webhooks
+// @Description Updates a webhook by its ID. Setting active to true re-enables a webhook that was disabled after repeated failures
+// @ID update-webhook
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param input body todo.UpdateWebhookInput true "Update params"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 422 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/webhooks/{id} [put]
+// @x-api-v1 true
+func (h *Handler) updateWebhook(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	var input todo.UpdateWebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(bindingError(err))
+		return
+	}
+
+	if err := h.requestServices(c).Webhook.Update(userId, id, input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary Delete webhook
+// @Security ApiKeyAuth
+// @Tags webhooks
+// @Description Deletes a webhook by its ID together with its delivery log
+// @ID delete-webhook
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/webhooks/{id} [delete]
+// @x-api-v1 true
+func (h *Handler) deleteWebhook(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	if err := h.requestServices(c).Webhook.Delete(userId, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+type getWebhookDeliveriesResponse struct {
+	Data       []todo.WebhookDelivery `json:"data"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+// @Summary Get webhook deliveries
+// @Security ApiKeyAuth
+// @Tags webhooks
+// @Description Retrieves the delivery log of a webhook, oldest first, with the outcome of the last attempt of every delivery
+// @ID get-webhook-deliveries
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param limit query int false "Page size, 50 by default and at most 100"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} getWebhookDeliveriesResponse
+// @Failure 400 {object} problemResponse
+// @Failure 404 {object} problemResponse
+// @Failure 500 {object} problemResponse
+// @Router /api/webhooks/{id}/deliveries [get]
+// @x-api-v1 true
+func (h *Handler) getWebhookDeliveries(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		logrus.Errorf("failed to get user id: %s", err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(badRequest("invalid id param"))
+		return
+	}
+
+	page, err := h.getPage(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	deliveries, more, err := h.requestServices(c).Webhook.GetDeliveries(userId, id, page)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := getWebhookDeliveriesResponse{
+		Data: deliveries,
+	}
+	if more {
+		response.NextCursor = h.encodeCursor(c, int(deliveries[len(deliveries)-1].Id))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
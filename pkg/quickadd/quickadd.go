@@ -0,0 +1,380 @@
+// Package quickadd parses a single line of free text such as
+// "Pay rent tomorrow 9am #home !high every month" into the parts of a todo
+// item: title, due date, tags, priority, target list and recurrence.
+//
+// Recognised words are removed from the line and whatever remains becomes
+// the title. Each kind of value is taken from its first occurrence only;
+// repeated date, time, priority, list or recurrence words stay in the title.
+// Words that are common in titles, such as "daily", "today", "noon" or
+// "friday", are only taken when nothing but recognised words follow them or
+// when a marker such as "every", "on" or "at" comes first.
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+)
+
+// Result is what the parser understood from the text.
+type Result struct {
+	Title      string     `json:"title"`
+	DueDate    *time.Time `json:"due_date"`
+	Tags       []string   `json:"tags"`
+	Priority   int        `json:"priority"`
+	List       string     `json:"list,omitempty"`
+	Recurrence string     `json:"recurrence,omitempty"`
+}
+
+var priorities = map[string]int{
+	"!low":    todo.PriorityLow,
+	"!1":      todo.PriorityLow,
+	"!medium": todo.PriorityMedium,
+	"!med":    todo.PriorityMedium,
+	"!2":      todo.PriorityMedium,
+	"!high":   todo.PriorityHigh,
+	"!3":      todo.PriorityHigh,
+}
+
+var recurrences = map[string]string{
+	"daily":   todo.RecurrenceDaily,
+	"weekly":  todo.RecurrenceWeekly,
+	"monthly": todo.RecurrenceMonthly,
+	"yearly":  todo.RecurrenceYearly,
+}
+
+var recurrenceUnits = map[string]string{
+	"day":   todo.RecurrenceDaily,
+	"week":  todo.RecurrenceWeekly,
+	"month": todo.RecurrenceMonthly,
+	"year":  todo.RecurrenceYearly,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+
+type clock struct {
+	hour, minute int
+}
+
+type parser struct {
+	now      time.Time
+	today    time.Time
+	date     *time.Time
+	clock    *clock
+	priority bool
+	result   Result
+}
+
+// Parse parses text, resolving relative dates such as "tomorrow" or
+// "friday" against now. The due date is expressed in now's location.
+func Parse(text string, now time.Time) Result {
+	p := parser{
+		now:   now,
+		today: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+	}
+
+	tokens := strings.Fields(text)
+	title := make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); {
+		if n := p.match(tokens[i:]); n > 0 {
+			i += n
+			continue
+		}
+		title = append(title, tokens[i])
+		i++
+	}
+
+	p.result.Title = strings.Join(title, " ")
+	p.result.DueDate = p.dueDate()
+
+	return p.result
+}
+
+// match consumes the recognised words at the start of tokens and reports how
+// many it took.
+func (p *parser) match(tokens []string) int {
+	word := normalize(tokens[0])
+
+	switch {
+	case strings.HasPrefix(word, "#") && len(word) > 1:
+		p.result.Tags = append(p.result.Tags, strings.TrimPrefix(word, "#"))
+		return 1
+	case strings.HasPrefix(word, "!") && !p.priority:
+		if priority, ok := priorities[word]; ok {
+			p.result.Priority = priority
+			p.priority = true
+			return 1
+		}
+	case strings.HasPrefix(tokens[0], "@") && len(tokens[0]) > 1 && p.result.List == "":
+		p.result.List = strings.TrimPrefix(tokens[0], "@")
+		return 1
+	}
+
+	if p.result.Recurrence == "" {
+		if n := p.matchRecurrence(tokens); n > 0 {
+			return n
+		}
+	}
+	if p.date == nil {
+		if n := p.matchDate(tokens); n > 0 {
+			return n
+		}
+	}
+	if p.clock == nil {
+		if n := p.matchClock(tokens); n > 0 {
+			return n
+		}
+	}
+
+	return 0
+}
+
+// matchRecurrence recognises "every day|week|month|year" and
+// "every <weekday>", the latter also setting the due date to the next such
+// weekday, as well as a trailing "daily", "weekly", "monthly" or "yearly".
+func (p *parser) matchRecurrence(tokens []string) int {
+	word := normalize(tokens[0])
+	if word != "every" {
+		recurrence, ok := recurrences[word]
+		if ok && p.trailing(tokens[1:], func(rest *parser) { rest.result.Recurrence = recurrence }) {
+			p.result.Recurrence = recurrence
+			return 1
+		}
+		return 0
+	}
+
+	if len(tokens) < 2 {
+		return 0
+	}
+
+	next := normalize(tokens[1])
+	if recurrence, ok := recurrenceUnits[next]; ok {
+		p.result.Recurrence = recurrence
+		return 2
+	}
+	if weekday, ok := weekdays[next]; ok {
+		p.result.Recurrence = todo.RecurrenceWeekly
+		if p.date == nil {
+			date := p.nextWeekday(weekday)
+			p.date = &date
+		}
+		return 2
+	}
+
+	return 0
+}
+
+// matchDate recognises "on|next <weekday>", "next week|month",
+// "in N days|weeks|months" and ISO dates. "today", "tomorrow" and weekdays
+// on their own are only taken when nothing but recognised words follow
+// them, and weekdays only when spelled out, so that titles like "Read Sun
+// Tzu" or "Friday meeting notes" keep their words.
+func (p *parser) matchDate(tokens []string) int {
+	word := normalize(tokens[0])
+	var next string
+	if len(tokens) > 1 {
+		next = normalize(tokens[1])
+	}
+
+	var date time.Time
+	n := 0
+	switch {
+	case word == "today" || word == "tomorrow":
+		if p.trailing(tokens[1:], takeDate) {
+			date, n = p.today, 1
+			if word == "tomorrow" {
+				date = date.AddDate(0, 0, 1)
+			}
+		}
+	case word == "on" || word == "next":
+		if weekday, ok := weekdays[next]; ok {
+			date, n = p.nextWeekday(weekday), 2
+		} else if word == "next" && next == "week" {
+			date, n = p.today.AddDate(0, 0, 7), 2
+		} else if word == "next" && next == "month" {
+			date, n = p.today.AddDate(0, 1, 0), 2
+		}
+	case word == "in":
+		if len(tokens) > 2 {
+			date, n = p.matchOffset(next, normalize(tokens[2]))
+		}
+	default:
+		if weekday, ok := weekdays[word]; ok && word == strings.ToLower(weekday.String()) && p.trailing(tokens[1:], takeDate) {
+			date, n = p.nextWeekday(weekday), 1
+		} else if parsed, err := time.ParseInLocation("2006-01-02", word, p.now.Location()); err == nil {
+			date, n = parsed, 1
+		}
+	}
+
+	if n > 0 {
+		p.date = &date
+	}
+	return n
+}
+
+// trailing reports whether the tokens are all recognised words once take
+// has recorded the value of the word before them.
+func (p *parser) trailing(tokens []string, take func(rest *parser)) bool {
+	rest := *p
+	rest.result.Tags = nil
+	take(&rest)
+
+	for i := 0; i < len(tokens); {
+		n := rest.match(tokens[i:])
+		if n == 0 {
+			return false
+		}
+		i += n
+	}
+
+	return true
+}
+
+func takeDate(rest *parser) {
+	rest.date = &rest.today
+}
+
+func takeClock(rest *parser) {
+	rest.clock = &clock{}
+}
+
+// matchOffset resolves "in N units", where N may also be "a" or "an".
+func (p *parser) matchOffset(count, unit string) (time.Time, int) {
+	var amount int
+	switch count {
+	case "a", "an":
+		amount = 1
+	default:
+		value, err := strconv.Atoi(count)
+		if err != nil || value < 1 {
+			return time.Time{}, 0
+		}
+		amount = value
+	}
+
+	switch strings.TrimSuffix(unit, "s") {
+	case "day":
+		return p.today.AddDate(0, 0, amount), 3
+	case "week":
+		return p.today.AddDate(0, 0, 7*amount), 3
+	case "month":
+		return p.today.AddDate(0, amount, 0), 3
+	}
+
+	return time.Time{}, 0
+}
+
+// matchClock recognises "9am", "9:30pm", "21:00", "noon" and "midnight",
+// optionally preceded by "at". A bare number is not taken as a time, and
+// "noon" or "midnight" without "at" only when they are trailing.
+func (p *parser) matchClock(tokens []string) int {
+	word := normalize(tokens[0])
+	n := 1
+	if word == "at" && len(tokens) > 1 {
+		word = normalize(tokens[1])
+		n = 2
+	}
+
+	c, ok := parseClock(word)
+	if !ok {
+		return 0
+	}
+	if n == 1 && (word == "noon" || word == "midnight") && !p.trailing(tokens[1:], takeClock) {
+		return 0
+	}
+
+	p.clock = &c
+	return n
+}
+
+func parseClock(word string) (clock, bool) {
+	switch word {
+	case "noon":
+		return clock{hour: 12}, true
+	case "midnight":
+		return clock{}, true
+	}
+
+	m := clockPattern.FindStringSubmatch(word)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return clock{}, false
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if minute > 59 {
+		return clock{}, false
+	}
+
+	switch m[3] {
+	case "":
+		if hour > 23 {
+			return clock{}, false
+		}
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return clock{}, false
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+
+	return clock{hour: hour, minute: minute}, true
+}
+
+// dueDate combines the parsed date and time. A time without a date means
+// today, or tomorrow once that time has passed; a date without a time means
+// the start of that day.
+func (p *parser) dueDate() *time.Time {
+	if p.date == nil && p.clock == nil {
+		return nil
+	}
+
+	date := p.today
+	if p.date != nil {
+		date = *p.date
+	}
+	if p.clock == nil {
+		return &date
+	}
+
+	due := time.Date(date.Year(), date.Month(), date.Day(), p.clock.hour, p.clock.minute, 0, 0, date.Location())
+	if p.date == nil && !due.After(p.now) {
+		due = due.AddDate(0, 0, 1)
+	}
+
+	return &due
+}
+
+// nextWeekday returns the next given weekday after today.
+func (p *parser) nextWeekday(weekday time.Weekday) time.Time {
+	days := (int(weekday) - int(p.today.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+
+	return p.today.AddDate(0, 0, days)
+}
+
+// normalize lowercases a word and drops trailing punctuation.
+func normalize(word string) string {
+	return strings.TrimRight(strings.ToLower(word), ",.;")
+}
@@ -0,0 +1,252 @@
+package quickadd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+)
+
+// now is Wednesday, 13 March 2024, 15:00 UTC.
+var now = time.Date(2024, time.March, 13, 15, 0, 0, 0, time.UTC)
+
+func at(year int, month time.Month, day, hour, minute int) *time.Time {
+	t := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	return &t
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Result
+	}{
+		{
+			name: "plain title",
+			text: "Buy milk",
+			want: Result{Title: "Buy milk"},
+		},
+		{
+			name: "full example",
+			text: "Pay rent tomorrow 9am #home !high every month",
+			want: Result{
+				Title:      "Pay rent",
+				DueDate:    at(2024, time.March, 14, 9, 0),
+				Tags:       []string{"home"},
+				Priority:   todo.PriorityHigh,
+				Recurrence: todo.RecurrenceMonthly,
+			},
+		},
+		{
+			name: "today",
+			text: "Call mom today",
+			want: Result{Title: "Call mom", DueDate: at(2024, time.March, 13, 0, 0)},
+		},
+		{
+			name: "weekday is the next one",
+			text: "Standup wednesday",
+			want: Result{Title: "Standup", DueDate: at(2024, time.March, 20, 0, 0)},
+		},
+		{
+			name: "weekday followed by recognised words",
+			text: "Dentist friday 4pm #health",
+			want: Result{Title: "Dentist", DueDate: at(2024, time.March, 15, 16, 0), Tags: []string{"health"}},
+		},
+		{
+			name: "abbreviated weekday after on",
+			text: "Standup on wed",
+			want: Result{Title: "Standup", DueDate: at(2024, time.March, 20, 0, 0)},
+		},
+		{
+			name: "bare abbreviated weekday stays in title",
+			text: "Read Sun Tzu",
+			want: Result{Title: "Read Sun Tzu"},
+		},
+		{
+			name: "trailing abbreviated weekday stays in title",
+			text: "Fix sat",
+			want: Result{Title: "Fix sat"},
+		},
+		{
+			name: "weekday within the title stays in it",
+			text: "Friday meeting notes",
+			want: Result{Title: "Friday meeting notes"},
+		},
+		{
+			name: "weekday before other words stays in title",
+			text: "Fix sat nav tomorrow",
+			want: Result{Title: "Fix sat nav", DueDate: at(2024, time.March, 14, 0, 0)},
+		},
+		{
+			name: "on weekday with time",
+			text: "Dentist on Friday at 4:30pm",
+			want: Result{Title: "Dentist", DueDate: at(2024, time.March, 15, 16, 30)},
+		},
+		{
+			name: "next weekday",
+			text: "Review next monday",
+			want: Result{Title: "Review", DueDate: at(2024, time.March, 18, 0, 0)},
+		},
+		{
+			name: "next week",
+			text: "Plan trip next week",
+			want: Result{Title: "Plan trip", DueDate: at(2024, time.March, 20, 0, 0)},
+		},
+		{
+			name: "in days",
+			text: "Renew passport in 3 days",
+			want: Result{Title: "Renew passport", DueDate: at(2024, time.March, 16, 0, 0)},
+		},
+		{
+			name: "in a month",
+			text: "Check tyres in a month",
+			want: Result{Title: "Check tyres", DueDate: at(2024, time.April, 13, 0, 0)},
+		},
+		{
+			name: "iso date",
+			text: "File taxes 2024-04-15",
+			want: Result{Title: "File taxes", DueDate: at(2024, time.April, 15, 0, 0)},
+		},
+		{
+			name: "time later today",
+			text: "Gym 18:00",
+			want: Result{Title: "Gym", DueDate: at(2024, time.March, 13, 18, 0)},
+		},
+		{
+			name: "passed time rolls over to tomorrow",
+			text: "Breakfast 8am",
+			want: Result{Title: "Breakfast", DueDate: at(2024, time.March, 14, 8, 0)},
+		},
+		{
+			name: "noon",
+			text: "Lunch tomorrow noon",
+			want: Result{Title: "Lunch", DueDate: at(2024, time.March, 14, 12, 0)},
+		},
+		{
+			name: "bare number is not a time",
+			text: "Read chapter 9",
+			want: Result{Title: "Read chapter 9"},
+		},
+		{
+			name: "at without time stays in title",
+			text: "Meet at office",
+			want: Result{Title: "Meet at office"},
+		},
+		{
+			name: "tags are lowercased",
+			text: "Fix bug #Work #urgent,",
+			want: Result{Title: "Fix bug", Tags: []string{"work", "urgent"}},
+		},
+		{
+			name: "numeric priority",
+			text: "Email boss !2",
+			want: Result{Title: "Email boss", Priority: todo.PriorityMedium},
+		},
+		{
+			name: "unknown priority stays in title",
+			text: "Wow !amazing",
+			want: Result{Title: "Wow !amazing"},
+		},
+		{
+			name: "only the first priority is taken",
+			text: "Task !low !high",
+			want: Result{Title: "Task !high", Priority: todo.PriorityLow},
+		},
+		{
+			name: "target list",
+			text: "Eggs @Groceries",
+			want: Result{Title: "Eggs", List: "Groceries"},
+		},
+		{
+			name: "daily",
+			text: "Water plants daily",
+			want: Result{Title: "Water plants", Recurrence: todo.RecurrenceDaily},
+		},
+		{
+			name: "every weekday sets the first occurrence",
+			text: "Take out bins every tuesday",
+			want: Result{
+				Title:      "Take out bins",
+				DueDate:    at(2024, time.March, 19, 0, 0),
+				Recurrence: todo.RecurrenceWeekly,
+			},
+		},
+		{
+			name: "explicit date wins over every weekday",
+			text: "Yoga tomorrow every thursday 7am",
+			want: Result{
+				Title:      "Yoga",
+				DueDate:    at(2024, time.March, 14, 7, 0),
+				Recurrence: todo.RecurrenceWeekly,
+			},
+		},
+		{
+			name: "only the first date is taken",
+			text: "Move on friday on monday",
+			want: Result{Title: "Move on monday", DueDate: at(2024, time.March, 15, 0, 0)},
+		},
+		{
+			name: "date word before another stays in title",
+			text: "Move today tomorrow",
+			want: Result{Title: "Move today", DueDate: at(2024, time.March, 14, 0, 0)},
+		},
+		{
+			name: "recurrence word within the title stays in it",
+			text: "Prepare daily standup notes",
+			want: Result{Title: "Prepare daily standup notes"},
+		},
+		{
+			name: "today within the title stays in it",
+			text: "Review what we did today with Bob",
+			want: Result{Title: "Review what we did today with Bob"},
+		},
+		{
+			name: "noon within the title stays in it",
+			text: "Plan noon meeting agenda",
+			want: Result{Title: "Plan noon meeting agenda"},
+		},
+		{
+			name: "at noon within the title",
+			text: "Lunch at noon with team",
+			want: Result{Title: "Lunch with team", DueDate: at(2024, time.March, 14, 12, 0)},
+		},
+		{
+			name: "nothing left for the title",
+			text: "tomorrow #home",
+			want: Result{DueDate: at(2024, time.March, 14, 0, 0), Tags: []string{"home"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.text, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) =\n%+v\nwant\n%+v", tt.text, format(got), format(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseUsesClockLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+10", 10*60*60)
+
+	got := Parse("Call tomorrow 9am", now.In(loc))
+	want := time.Date(2024, time.March, 15, 9, 0, 0, 0, loc)
+	if got.DueDate == nil || !got.DueDate.Equal(want) {
+		t.Errorf("due date = %v, want %v", got.DueDate, want)
+	}
+}
+
+// format dereferences the due date so failures print readable values.
+func format(r Result) interface{} {
+	type result struct {
+		Result
+		Due string
+	}
+	due := "<nil>"
+	if r.DueDate != nil {
+		due = r.DueDate.Format(time.RFC3339)
+	}
+	return result{r, due}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+)
+
+const auditColumns = "id, actor_id, action, entity, entity_id, list_id, before, after, request_id, ip, created_at"
+
+type AuditPostgres struct {
+	db DB
+}
+
+func NewAuditPostgres(db DB) *AuditPostgres {
+	return &AuditPostgres{db: db}
+}
+
+func (r *AuditPostgres) Create(entry todo.AuditEntry) error {
+	query := fmt.Sprintf(`INSERT INTO %s (actor_id, action, entity, entity_id, list_id, before, after, request_id, ip)
+							VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`, auditLogTable)
+	_, err := r.db.Exec(query, entry.ActorId, entry.Action, entry.Entity, entry.EntityId, entry.ListId,
+		jsonArg(entry.Before), jsonArg(entry.After), entry.RequestId, entry.IP)
+
+	return err
+}
+
+// GetByList returns the entries of the list and its items, oldest first.
+func (r *AuditPostgres) GetByList(listId int, page todo.Page) ([]todo.AuditEntry, error) {
+	var entries []todo.AuditEntry
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE list_id = $1 AND id > $2 ORDER BY id LIMIT $3", auditColumns, auditLogTable)
+	err := r.db.Select(&entries, query, listId, page.AfterId, limitArg(page))
+
+	return entries, err
+}
+
+// Query returns the entries matching the filter, oldest first.
+func (r *AuditPostgres) Query(filter todo.AuditFilter, page todo.Page) ([]todo.AuditEntry, error) {
+	conditions := []string{"id > $1"}
+	args := []interface{}{page.AfterId}
+	argId := 2
+
+	add := func(condition string, arg interface{}) {
+		conditions = append(conditions, fmt.Sprintf(condition, argId))
+		args = append(args, arg)
+		argId++
+	}
+
+	if filter.ActorId != nil {
+		add("actor_id = $%d", *filter.ActorId)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.Entity != "" {
+		add("entity = $%d", filter.Entity)
+	}
+	if filter.EntityId != nil {
+		add("entity_id = $%d", *filter.EntityId)
+	}
+	if filter.ListId != nil {
+		add("list_id = $%d", *filter.ListId)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+
+	var entries []todo.AuditEntry
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY id LIMIT $%d",
+		auditColumns, auditLogTable, strings.Join(conditions, " AND "), argId)
+	err := r.db.Select(&entries, query, append(args, limitArg(page))...)
+
+	return entries, err
+}
+
+// jsonArg passes a JSON document as text so that the server parses it,
+// and nil as NULL.
+func jsonArg(data json.RawMessage) interface{} {
+	if data == nil {
+		return nil
+	}
+
+	return string(data)
+}
@@ -2,15 +2,16 @@ package repository
 
 import (
 	"fmt"
+	"time"
+
 	"github.com/MyNameIsWhaaat/todo-app"
-	"github.com/jmoiron/sqlx"
 )
 
 type AuthPostgres struct {
-	db *sqlx.DB
+	db DB
 }
 
-func NewAuthPostgres(db *sqlx.DB) *AuthPostgres {
+func NewAuthPostgres(db DB) *AuthPostgres {
 	return &AuthPostgres{db: db}
 }
 
@@ -20,7 +21,7 @@ func (r *AuthPostgres) CreateUser(user todo.User) (int, error){
 	
 	row:= r.db.QueryRow(query, user.Name, user.Username, user.Password)
 	if err:= row.Scan(&id); err!=nil{
-		return 0, err
+		return 0, translateError(err)
 	}
 
 	return id, nil
@@ -31,5 +32,27 @@ func (r *AuthPostgres) GetUser(username, password string) (todo.User, error){
 	query:=fmt.Sprintf("SELECT id FROM %s WHERE username=$1 AND password_hash=$2", usersTable)
 	err:= r.db.Get(&user, query, username, password)
 
-	return user, err
-}
\ No newline at end of file
+	return user, translateError(err)
+}
+func (r *AuthPostgres) IsAdmin(userId int) (bool, error){
+	var isAdmin bool
+	query:=fmt.Sprintf("SELECT is_admin FROM %s WHERE id=$1", usersTable)
+	err:= r.db.Get(&isAdmin, query, userId)
+
+	return isAdmin, translateError(err)
+}
+
+// UseEventTicket marks the ticket as used, failing with todo.ErrConflict
+// when it was used before. Tickets past their expiry are forgotten, since
+// they are refused anyway.
+func (r *AuthPostgres) UseEventTicket(ticketId string, expiresAt time.Time) error{
+	purgeQuery := fmt.Sprintf("DELETE FROM %s WHERE expires_at < now()", usedEventTicketsTable)
+	if _, err := r.db.Exec(purgeQuery); err != nil{
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (id, expires_at) VALUES ($1, $2)", usedEventTicketsTable)
+	_, err := r.db.Exec(query, ticketId, expiresAt)
+
+	return translateError(err)
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DB is the database handle the repositories run their queries on. It is
+// either the connection pool or an open transaction, in which case the
+// transactions the repositories begin become savepoints inside it.
+type DB interface {
+	Get(dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Begin() (Tx, error)
+}
+
+// Tx is a transaction begun on a DB.
+type Tx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	QueryRowx(query string, args ...interface{}) *sqlx.Row
+	Commit() error
+	Rollback() error
+}
+
+// pool runs queries on the connection pool.
+type pool struct {
+	*sqlx.DB
+}
+
+func (p pool) Begin() (Tx, error) {
+	return p.DB.Beginx()
+}
+
+// txDB runs queries inside one outer transaction. It is not safe for
+// concurrent use.
+type txDB struct {
+	*sqlx.Tx
+	savepoints int
+}
+
+func (t *txDB) Begin() (Tx, error) {
+	t.savepoints++
+	sp := &savepoint{tx: t.Tx, name: fmt.Sprintf("sp_%d", t.savepoints)}
+	if _, err := t.Exec("SAVEPOINT " + sp.name); err != nil {
+		return nil, err
+	}
+
+	return sp, nil
+}
+
+// savepoint is a nested transaction of a txDB.
+type savepoint struct {
+	tx   *sqlx.Tx
+	name string
+}
+
+func (s *savepoint) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.tx.Exec(query, args...)
+}
+
+func (s *savepoint) QueryRow(query string, args ...interface{}) *sql.Row {
+	return s.tx.QueryRow(query, args...)
+}
+
+func (s *savepoint) QueryRowx(query string, args ...interface{}) *sqlx.Row {
+	return s.tx.QueryRowx(query, args...)
+}
+
+func (s *savepoint) Commit() error {
+	_, err := s.tx.Exec("RELEASE SAVEPOINT " + s.name)
+	return err
+}
+
+func (s *savepoint) Rollback() error {
+	_, err := s.tx.Exec("ROLLBACK TO SAVEPOINT " + s.name)
+	return err
+}
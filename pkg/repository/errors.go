@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/lib/pq"
+)
+
+const uniqueViolation = "23505"
+
+// translateError converts driver errors into domain errors.
+func translateError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return todo.ErrNotFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return fmt.Errorf("%w: %s", todo.ErrConflict, pqErr.Detail)
+	}
+
+	return err
+}
+
+// checkAffected reports todo.ErrNotFound when a statement touched no rows,
+// which happens when the row is missing or belongs to another user.
+func checkAffected(res sql.Result, err error) error {
+	if err != nil {
+		return translateError(err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return todo.ErrNotFound
+	}
+
+	return nil
+}
+
+// checkVersioned is checkAffected for statements guarded by an expected
+// version, where 0 means any. When no row was touched, exists tells a
+// missing row apart from one that has moved on to another version.
+func checkVersioned(res sql.Result, err error, version int, exists func() error) error {
+	err = checkAffected(res, err)
+	if version == 0 || !errors.Is(err, todo.ErrNotFound) {
+		return err
+	}
+
+	if err := exists(); err != nil {
+		return err
+	}
+
+	return todo.ErrPreconditionFailed
+}
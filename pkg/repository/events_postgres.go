@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// eventsChannel is the notification channel change events are sent on.
+const eventsChannel = "todo_events"
+
+const (
+	listenerMinReconnect = 10 * time.Second
+	listenerMaxReconnect = time.Minute
+	listenerPingInterval = 90 * time.Second
+)
+
+// EventsPostgres publishes events with NOTIFY. Inside a transaction the
+// notification is only sent when the transaction commits.
+type EventsPostgres struct {
+	db DB
+}
+
+func NewEventsPostgres(db DB) *EventsPostgres {
+	return &EventsPostgres{db: db}
+}
+
+func (r *EventsPostgres) Publish(event todo.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec("SELECT pg_notify($1, $2)", eventsChannel, string(payload))
+	return err
+}
+
+// EventListener receives the events published by every replica with
+// LISTEN.
+type EventListener struct {
+	listener *pq.Listener
+}
+
+func NewEventListener(cfg Config) (*EventListener, error) {
+	listener := pq.NewListener(cfg.dataSourceName(), listenerMinReconnect, listenerMaxReconnect,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				logrus.Errorf("event listener: %s", err.Error())
+			}
+		})
+
+	if err := listener.Listen(eventsChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return &EventListener{listener: listener}, nil
+}
+
+// Receive waits for the next event. It returns a nil event when the
+// connection was re-established, since events may have been missed while
+// it was down.
+func (l *EventListener) Receive(ctx context.Context) (*todo.Event, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(listenerPingInterval):
+			go l.listener.Ping()
+		case notification := <-l.listener.Notify:
+			if notification == nil {
+				return nil, nil
+			}
+
+			var event todo.Event
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				logrus.Errorf("event listener: malformed event: %s", err.Error())
+				continue
+			}
+			return &event, nil
+		}
+	}
+}
+
+func (l *EventListener) Close() error {
+	return l.listener.Close()
+}
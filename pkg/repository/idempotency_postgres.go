@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+)
+
+type IdempotencyPostgres struct {
+	db DB
+}
+
+func NewIdempotencyPostgres(db DB) *IdempotencyPostgres {
+	return &IdempotencyPostgres{db: db}
+}
+
+// Reserve claims the key for a new request unless it is already held by a
+// record created after expiredBefore, or by a request still in progress
+// that reserved it after abandonedBefore. It reports whether the key was
+// claimed.
+func (r *IdempotencyPostgres) Reserve(userId int, key, fingerprint string, expiredBefore, abandonedBefore time.Time) (bool, error) {
+	query := fmt.Sprintf(`INSERT INTO %[1]s (user_id, key, fingerprint) VALUES ($1, $2, $3)
+							ON CONFLICT (user_id, key) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, status = 0, content_type = '', location = '', body = NULL, created_at = now()
+							WHERE %[1]s.created_at < $4 OR (%[1]s.status = 0 AND %[1]s.created_at < $5)`, idempotencyKeysTable)
+	res, err := r.db.Exec(query, userId, key, fingerprint, expiredBefore, abandonedBefore)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+	return count > 0, err
+}
+
+func (r *IdempotencyPostgres) Get(userId int, key string) (todo.IdempotencyRecord, error) {
+	var record todo.IdempotencyRecord
+	query := fmt.Sprintf("SELECT fingerprint, status, content_type, location, body FROM %s WHERE user_id = $1 AND key = $2", idempotencyKeysTable)
+	err := r.db.Get(&record, query, userId, key)
+
+	return record, translateError(err)
+}
+
+// Complete stores the response of the request holding the key.
+func (r *IdempotencyPostgres) Complete(userId int, key string, record todo.IdempotencyRecord) error {
+	query := fmt.Sprintf("UPDATE %s SET status = $3, content_type = $4, location = $5, body = $6 WHERE user_id = $1 AND key = $2", idempotencyKeysTable)
+
+	return checkAffected(r.db.Exec(query, userId, key, record.Status, record.ContentType, record.Location, record.Body))
+}
+
+// Release frees a key whose request did not complete so it can be retried.
+func (r *IdempotencyPostgres) Release(userId int, key string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND key = $2 AND status = 0", idempotencyKeysTable)
+	_, err := r.db.Exec(query, userId, key)
+
+	return err
+}
+
+func (r *IdempotencyPostgres) Purge(before time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE created_at < $1", idempotencyKeysTable)
+	res, err := r.db.Exec(query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+)
+
+type ListTemplatePostgres struct {
+	db DB
+}
+
+func NewListTemplatePostgres(db DB) *ListTemplatePostgres {
+	return &ListTemplatePostgres{db: db}
+}
+
+func (r *ListTemplatePostgres) Create(userId int, template todo.ListTemplate) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	createTemplateQuery := fmt.Sprintf("INSERT INTO %s (user_id, title, description) VALUES ($1, $2, $3) RETURNING id", listTemplatesTable)
+	row := tx.QueryRow(createTemplateQuery, userId, template.Title, template.Description)
+	if err := row.Scan(&id); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	createItemQuery := fmt.Sprintf("INSERT INTO %s (template_id, title, description) VALUES ($1, $2, $3)", templateItemsTable)
+	for _, item := range template.Items {
+		if _, err := tx.Exec(createItemQuery, id, item.Title, item.Description); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *ListTemplatePostgres) GetAll(userId int) ([]todo.ListTemplate, error) {
+	var templates []todo.ListTemplate
+	query := fmt.Sprintf("SELECT id, title, description FROM %s WHERE user_id = $1 ORDER BY id", listTemplatesTable)
+	err := r.db.Select(&templates, query, userId)
+
+	return templates, err
+}
+
+func (r *ListTemplatePostgres) GetById(userId, templateId int) (todo.ListTemplate, error) {
+	var template todo.ListTemplate
+	query := fmt.Sprintf("SELECT id, title, description FROM %s WHERE user_id = $1 AND id = $2", listTemplatesTable)
+	if err := r.db.Get(&template, query, userId, templateId); err != nil {
+		return template, translateError(err)
+	}
+
+	itemsQuery := fmt.Sprintf("SELECT id, title, description FROM %s WHERE template_id = $1 ORDER BY id", templateItemsTable)
+	err := r.db.Select(&template.Items, itemsQuery, templateId)
+
+	return template, err
+}
+
+func (r *ListTemplatePostgres) Delete(userId, templateId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND id = $2", listTemplatesTable)
+
+	return checkAffected(r.db.Exec(query, userId, templateId))
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/lib/pq"
+)
+
+// OutboxPostgres stores events in the outbox table. Written inside a
+// transaction, an event is only relayed if the transaction commits.
+type OutboxPostgres struct {
+	db DB
+}
+
+func NewOutboxPostgres(db DB) *OutboxPostgres {
+	return &OutboxPostgres{db: db}
+}
+
+func (r *OutboxPostgres) Publish(event todo.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (event_id, event_type, payload) VALUES ($1, $2, $3)", outboxTable)
+	_, err = r.db.Exec(query, event.Id, event.Type, string(payload))
+
+	return translateError(err)
+}
+
+// ClaimDue returns up to limit unpublished messages that are due, oldest
+// first, and pushes their next attempt back by lease so that other relays
+// skip them meanwhile. Messages of a relay that dies are picked up again
+// once the lease runs out.
+func (r *OutboxPostgres) ClaimDue(limit int, lease time.Duration) ([]todo.OutboxMessage, error) {
+	var messages []todo.OutboxMessage
+	query := fmt.Sprintf(`UPDATE %[1]s SET next_attempt_at = now() + $1 * interval '1 millisecond'
+							WHERE id IN (
+								SELECT id FROM %[1]s WHERE published_at IS NULL AND next_attempt_at <= now()
+								ORDER BY id LIMIT $2 FOR UPDATE SKIP LOCKED)
+							RETURNING id, event_id, payload, attempts, created_at`, outboxTable)
+	if err := r.db.Select(&messages, query, lease.Milliseconds(), limit); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the order of the subquery.
+	slices.SortFunc(messages, func(a, b todo.OutboxMessage) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+
+	return messages, nil
+}
+
+func (r *OutboxPostgres) MarkPublished(ids []int64) error {
+	query := fmt.Sprintf("UPDATE %s SET published_at = now(), attempts = attempts + 1, last_error = NULL WHERE id = ANY($1)", outboxTable)
+	_, err := r.db.Exec(query, pq.Array(ids))
+
+	return err
+}
+
+func (r *OutboxPostgres) MarkFailed(id int64, lastError string, nextAttemptAt time.Time) error {
+	query := fmt.Sprintf("UPDATE %s SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2 WHERE id = $3", outboxTable)
+	_, err := r.db.Exec(query, lastError, nextAttemptAt, id)
+
+	return err
+}
+
+// Purge deletes messages published before the given time.
+func (r *OutboxPostgres) Purge(before time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE published_at < $1", outboxTable)
+	res, err := r.db.Exec(query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/MyNameIsWhaaat/todo-app"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -11,6 +14,17 @@ const (
 	usersListsTable ="users_lists"
 	todoItemsTable  ="todo_items"
 	listsItemsTable ="lists_items"
+	listTemplatesTable ="list_templates"
+	templateItemsTable ="template_items"
+	itemTagsTable ="item_tags"
+	savedFiltersTable ="saved_filters"
+	idempotencyKeysTable ="idempotency_keys"
+	webhooksTable ="webhooks"
+	webhookDeliveriesTable ="webhook_deliveries"
+	outboxTable ="outbox"
+	auditLogTable ="audit_log"
+	itemRevisionsTable ="item_revisions"
+	usedEventTicketsTable ="used_event_tickets"
 )
 
 type Config struct {
@@ -22,9 +36,24 @@ type Config struct {
 	SSLMode  string
 }
 
+// likeEscaper escapes LIKE wildcards in user supplied search text.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// limitArg returns the LIMIT argument for a page; NULL means no limit.
+func limitArg(page todo.Page) interface{} {
+	if page.Limit == 0 {
+		return nil
+	}
+	return page.Limit
+}
+
+func (cfg Config) dataSourceName() string {
+	return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.Username, cfg.DBName, cfg.Password, cfg.SSLMode)
+}
+
 func NewPostgresDB(cfg Config) (*sqlx.DB, error) {
-	db, err := sqlx.Open("postgres", fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.Username, cfg.DBName, cfg.Password, cfg.SSLMode))
+	db, err := sqlx.Open("postgres", cfg.dataSourceName())
 
 	if err != nil {
 		return nil, err
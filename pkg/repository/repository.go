@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/MyNameIsWhaaat/todo-app"
 	"github.com/jmoiron/sqlx"
 )
@@ -8,34 +11,183 @@ import (
 type Authorization interface{
 	CreateUser(user todo.User) (int, error)
 	GetUser(username, password string) (todo.User, error)
+	IsAdmin(userId int) (bool, error)
+	UseEventTicket(ticketId string, expiresAt time.Time) error
 }
 
 type TodoList interface{
-	Create(userId int, list todo.TodoList) (int, error)
-	GetAll(userId int) ([]todo.TodoList, error)
+	Create(userId int, list todo.TodoList) (todo.TodoList, error)
+	GetAll(userId int, archived bool, page todo.Page) ([]todo.TodoList, error)
 	GetById(userId, listId int) (todo.TodoList, error)
-	Update(userId, listId int, input todo.UpdateListInput) error
-	Delete(userId, listId int) error
+	GetByIds(userId int, listIds []int) ([]todo.TodoList, error)
+	GetMembers(userId int, listIds []int) ([]todo.ListMember, error)
+	Update(userId, listId int, input todo.UpdateListInput, version int) error
+	Delete(userId, listId int, version int) error
+	Archive(userId, listId int) error
+	Unarchive(userId, listId int) error
+	CreateWithItems(userId int, list todo.TodoList, items []todo.TodoItem) (int, error)
 }
 
 type TodoItem interface{
-	Create(listId int, item todo.TodoItem) (int, error)
-	GetAll(userId int, listId int) ([]todo.TodoItem, error)
+	Create(listId int, item todo.TodoItem) (todo.TodoItem, error)
+	GetAll(userId int, listId int, filter todo.ItemFilter, page todo.Page) ([]todo.TodoItem, error)
 	GetById(userId int, itemId int) (todo.TodoItem, error)
-	Update(userId, itemId int, input todo.UpdateItemInput) error
-	Delete(userId, itemId int) error
+	GetForUpdate(userId int, itemId int) (todo.TodoItem, error)
+	GetByLists(userId int, listIds []int) ([]todo.TodoItem, error)
+	GetByIds(userId int, itemIds []int) ([]todo.TodoItem, error)
+	GetByIdsForUpdate(userId int, itemIds []int) ([]todo.TodoItem, error)
+	GetListId(userId, itemId int) (int, error)
+	GetListIds(userId int, itemIds []int) (map[int]int, error)
+	GetByFilter(userId int, filter todo.FilterQuery, page todo.Page) ([]todo.TodoItem, error)
+	GetDueBetween(userId int, from, to *time.Time) ([]todo.TodoItem, error)
+	Update(userId, itemId int, input todo.UpdateItemInput, version int) error
+	Delete(userId, itemId int, version int) error
+	Bulk(userId int, input todo.BulkItemInput) ([]todo.BulkItemResult, error)
+}
+
+type ListTemplate interface{
+	Create(userId int, template todo.ListTemplate) (int, error)
+	GetAll(userId int) ([]todo.ListTemplate, error)
+	GetById(userId, templateId int) (todo.ListTemplate, error)
+	Delete(userId, templateId int) error
+}
+
+type Trash interface{
+	GetLists(userId int) ([]todo.TodoList, error)
+	GetItems(userId int) ([]todo.TodoItem, error)
+	RestoreList(userId, listId int) error
+	RestoreItem(userId, itemId int) error
+	Purge(before time.Time) (int64, error)
+}
+
+type Search interface{
+	Search(userId int, query string, limit int) ([]todo.SearchResult, error)
+}
+
+type Settings interface{
+	Get(userId int) (todo.UserSettings, error)
+	Update(userId int, input todo.UpdateSettingsInput) error
+}
+
+type SavedFilter interface{
+	Create(userId int, filter todo.SavedFilter) (int, error)
+	GetAll(userId int) ([]todo.SavedFilter, error)
+	GetById(userId, filterId int) (todo.SavedFilter, error)
+	Update(userId, filterId int, input todo.UpdateSavedFilterInput) error
+	Delete(userId, filterId int) error
+}
+
+type Idempotency interface{
+	Reserve(userId int, key, fingerprint string, expiredBefore, abandonedBefore time.Time) (bool, error)
+	Get(userId int, key string) (todo.IdempotencyRecord, error)
+	Complete(userId int, key string, record todo.IdempotencyRecord) error
+	Release(userId int, key string) error
+	Purge(before time.Time) (int64, error)
+}
+
+type Webhook interface{
+	Create(userId int, input todo.WebhookInput) (int, error)
+	GetAll(userId int) ([]todo.Webhook, error)
+	GetById(userId, webhookId int) (todo.Webhook, error)
+	Update(userId, webhookId int, input todo.UpdateWebhookInput) error
+	Delete(userId, webhookId int) error
+	GetDeliveries(userId, webhookId int, page todo.Page) ([]todo.WebhookDelivery, error)
+	Enqueue(event todo.Event) error
+	ClaimDue(limit int, lease time.Duration) ([]todo.WebhookDispatch, error)
+	RecordAttempt(attempt todo.WebhookAttempt) (int, error)
+	Disable(webhookId int) error
+}
+
+type Events interface{
+	Publish(event todo.Event) error
+}
+
+type Outbox interface{
+	Publish(event todo.Event) error
+	ClaimDue(limit int, lease time.Duration) ([]todo.OutboxMessage, error)
+	MarkPublished(ids []int64) error
+	MarkFailed(id int64, lastError string, nextAttemptAt time.Time) error
+	Purge(before time.Time) (int64, error)
+}
+
+type Audit interface{
+	Create(entry todo.AuditEntry) error
+	GetByList(listId int, page todo.Page) ([]todo.AuditEntry, error)
+	Query(filter todo.AuditFilter, page todo.Page) ([]todo.AuditEntry, error)
+}
+
+type ItemRevision interface{
+	Create(revision todo.ItemRevision) error
+	GetByItem(itemId int, page todo.Page) ([]todo.ItemRevision, error)
+	GetByRevision(itemId, revision int) (todo.ItemRevision, error)
+}
+
+type EventFeed interface{
+	Receive(ctx context.Context) (*todo.Event, error)
 }
 
 type Repository struct{
 	Authorization
 	TodoList
 	TodoItem
+	ListTemplate
+	Trash
+	Search
+	Settings
+	SavedFilter
+	Idempotency
+	Webhook
+	Events
+	Outbox
+	Audit
+	ItemRevision
+
+	pool *sqlx.DB
 }
 
 func NewRepository(db *sqlx.DB)  *Repository{
+	repos := newRepository(pool{db})
+	repos.pool = db
+
+	return repos
+}
+
+func newRepository(db DB) *Repository{
 	return &Repository{
 		Authorization: NewAuthPostgres(db),
 		TodoList: NewTodoListPostgres(db),
 		TodoItem: NewTodoItemPostgres(db),
+		ListTemplate: NewListTemplatePostgres(db),
+		Trash: NewTrashPostgres(db),
+		Search: NewSearchPostgres(db),
+		Settings: NewSettingsPostgres(db),
+		SavedFilter: NewSavedFilterPostgres(db),
+		Idempotency: NewIdempotencyPostgres(db),
+		Webhook: NewWebhookPostgres(db),
+		Events: NewEventsPostgres(db),
+		Outbox: NewOutboxPostgres(db),
+		Audit: NewAuditPostgres(db),
+		ItemRevision: NewItemRevisionPostgres(db),
 	}
-}
\ No newline at end of file
+}
+
+// Transaction runs fn with repositories that share one database
+// transaction, committing it when fn returns nil and rolling it back
+// otherwise. Repositories that already run inside a transaction join it.
+func (r *Repository) Transaction(fn func(repos *Repository) error) error{
+	if r.pool == nil{
+		return fn(r)
+	}
+
+	tx, err := r.pool.Beginx()
+	if err != nil{
+		return err
+	}
+
+	if err := fn(newRepository(&txDB{Tx: tx})); err != nil{
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+)
+
+const revisionColumns = "item_id, revision, title, description, done, due_date, priority, tags, recurrence, replaced_by, replaced_at"
+
+type ItemRevisionPostgres struct {
+	db DB
+}
+
+func NewItemRevisionPostgres(db DB) *ItemRevisionPostgres {
+	return &ItemRevisionPostgres{db: db}
+}
+
+// Create stores the revision. A version is only ever one state of the
+// item, so a revision already stored by a concurrent change is kept.
+func (r *ItemRevisionPostgres) Create(revision todo.ItemRevision) error {
+	if revision.Tags == nil {
+		revision.Tags = todo.Tags{}
+	}
+	tags, err := json.Marshal(revision.Tags)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (item_id, revision, title, description, done, due_date, priority, tags, recurrence, replaced_by)
+							VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT (item_id, revision) DO NOTHING`,
+		itemRevisionsTable)
+	_, err = r.db.Exec(query, revision.ItemId, revision.Revision, revision.Title, revision.Description, revision.Done,
+		revision.DueDate, revision.Priority, string(tags), revision.Recurrence, revision.ReplacedBy)
+
+	return err
+}
+
+// GetByItem returns the revisions of the item, oldest first. Pages are
+// keyed by revision.
+func (r *ItemRevisionPostgres) GetByItem(itemId int, page todo.Page) ([]todo.ItemRevision, error) {
+	var revisions []todo.ItemRevision
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE item_id = $1 AND revision > $2 ORDER BY revision LIMIT $3",
+		revisionColumns, itemRevisionsTable)
+	err := r.db.Select(&revisions, query, itemId, page.AfterId, limitArg(page))
+
+	return revisions, err
+}
+
+func (r *ItemRevisionPostgres) GetByRevision(itemId, revision int) (todo.ItemRevision, error) {
+	var itemRevision todo.ItemRevision
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE item_id = $1 AND revision = $2", revisionColumns, itemRevisionsTable)
+	err := r.db.Get(&itemRevision, query, itemId, revision)
+
+	return itemRevision, translateError(err)
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+)
+
+// The snippets are HTML with the matches wrapped in <mark>. ts_headline
+// marks them with private use characters instead, stripped from the text
+// beforehand, so that the text can be escaped before they become tags.
+const (
+	searchMarkStart = "\uE000"
+	searchMarkStop  = "\uE001"
+
+	searchHeadlineOptions = "StartSel=" + searchMarkStart + ", StopSel=" + searchMarkStop + ", MaxFragments=2, MaxWords=20, MinWords=5"
+)
+
+var searchMarkReplacer = strings.NewReplacer(searchMarkStart, "<mark>", searchMarkStop, "</mark>")
+
+type SearchPostgres struct {
+	db DB
+}
+
+func NewSearchPostgres(db DB) *SearchPostgres {
+	return &SearchPostgres{db: db}
+}
+
+// Search matches the query against the search vectors of every list and
+// item the user can access, parsing it with the user's search language.
+func (r *SearchPostgres) Search(userId int, text string, limit int) ([]todo.SearchResult, error) {
+	var results []todo.SearchResult
+	query := fmt.Sprintf(`WITH q AS (SELECT search_language AS lang, websearch_to_tsquery(search_language, $2) AS query FROM %s WHERE id = $1)
+		SELECT '%s' AS type, tl.id, tl.id AS list_id, tl.title,
+			ts_headline(q.lang, translate(tl.title || ' ' || coalesce(tl.description, ''), $5, ''), q.query, $4) AS snippet,
+			ts_rank(tl.search_vector, q.query) AS rank
+		FROM q, %s tl INNER JOIN %s ul on ul.list_id = tl.id
+		WHERE ul.user_id = $1 AND tl.deleted_at IS NULL AND tl.search_vector @@ q.query
+		UNION ALL
+		SELECT '%s' AS type, ti.id, li.list_id, ti.title,
+			ts_headline(q.lang, translate(ti.title || ' ' || coalesce(ti.description, ''), $5, ''), q.query, $4) AS snippet,
+			ts_rank(ti.search_vector, q.query) AS rank
+		FROM q, %s ti INNER JOIN %s li on li.item_id = ti.id
+			INNER JOIN %s ul on ul.list_id = li.list_id INNER JOIN %s tl on tl.id = li.list_id
+		WHERE ul.user_id = $1 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL AND ti.search_vector @@ q.query
+		ORDER BY rank DESC, type, id LIMIT $3`,
+		usersTable, todo.SearchResultList, todoListsTable, usersListsTable,
+		todo.SearchResultItem, todoItemsTable, listsItemsTable, usersListsTable, todoListsTable)
+	err := r.db.Select(&results, query, userId, text, limit, searchHeadlineOptions, searchMarkStart+searchMarkStop)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].Snippet = searchMarkReplacer.Replace(html.EscapeString(results[i].Snippet))
+	}
+
+	return results, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+)
+
+type SettingsPostgres struct {
+	db DB
+}
+
+func NewSettingsPostgres(db DB) *SettingsPostgres {
+	return &SettingsPostgres{db: db}
+}
+
+func (r *SettingsPostgres) Get(userId int) (todo.UserSettings, error) {
+	var settings todo.UserSettings
+	query := fmt.Sprintf("SELECT search_language, timezone FROM %s WHERE id = $1", usersTable)
+	err := r.db.Get(&settings, query, userId)
+
+	return settings, translateError(err)
+}
+
+// Update stores the settings. A new search language is also applied to
+// the lists the user owns and their items so that their search vectors get
+// rebuilt. Lists shared with the user keep their owner's language. The
+// owner of a list is the user who created it, whose users_lists row comes
+// first.
+func (r *SettingsPostgres) Update(userId int, input todo.UpdateSettingsInput) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if input.SearchLanguage != nil {
+		queries := []string{
+			fmt.Sprintf("UPDATE %s SET search_language = $2 WHERE id = $1", usersTable),
+			fmt.Sprintf(`UPDATE %[1]s tl SET search_language = $2 FROM %[2]s ul
+							WHERE tl.id = ul.list_id AND ul.user_id = $1 AND tl.search_language <> $2
+							AND ul.id = (SELECT min(id) FROM %[2]s WHERE list_id = ul.list_id)`,
+				todoListsTable, usersListsTable),
+			fmt.Sprintf(`UPDATE %[1]s ti SET search_language = $2 FROM %[2]s li, %[3]s ul
+							WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $1 AND ti.search_language <> $2
+							AND ul.id = (SELECT min(id) FROM %[3]s WHERE list_id = ul.list_id)`,
+				todoItemsTable, listsItemsTable, usersListsTable),
+		}
+
+		for _, query := range queries {
+			if _, err := tx.Exec(query, userId, *input.SearchLanguage); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	if input.Timezone != nil {
+		query := fmt.Sprintf("UPDATE %s SET timezone = $2 WHERE id = $1", usersTable)
+		if _, err := tx.Exec(query, userId, *input.Timezone); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
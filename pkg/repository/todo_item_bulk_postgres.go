@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/lib/pq"
+)
+
+// Bulk applies one operation to many items in a single transaction. Every
+// item is attempted so that the report is complete; if any of them fails
+// nothing is committed and a *todo.BulkError carrying the report is
+// returned.
+func (r *TodoItemPostgres) Bulk(userId int, input todo.BulkItemInput) ([]todo.BulkItemResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Operation == todo.BulkMove {
+		if err := lockWritableList(tx, userId, *input.ListId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	results := make([]todo.BulkItemResult, 0, len(input.Ids))
+	failed := false
+	for _, itemId := range input.Ids {
+		result := todo.BulkItemResult{Id: itemId, Status: todo.BulkStatusOk}
+
+		err := applyBulk(tx, userId, itemId, input)
+		switch {
+		case err == nil:
+		case errors.Is(err, todo.ErrNotFound), errors.Is(err, todo.ErrConflict):
+			result.Status, result.Error = todo.BulkStatusFailed, err.Error()
+			failed = true
+		default:
+			tx.Rollback()
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	if failed {
+		tx.Rollback()
+		for i := range results {
+			if results[i].Status == todo.BulkStatusOk {
+				results[i].Status = todo.BulkStatusRolledBack
+			}
+		}
+		return nil, &todo.BulkError{Results: results}
+	}
+
+	return results, tx.Commit()
+}
+
+func applyBulk(tx Tx, userId, itemId int, input todo.BulkItemInput) error {
+	if err := lockWritableItem(tx, userId, itemId); err != nil {
+		return err
+	}
+
+	switch input.Operation {
+	case todo.BulkUpdate:
+		return updateItem(tx, userId, itemId, *input.Update, 0)
+	case todo.BulkDelete:
+		query := fmt.Sprintf("UPDATE %s SET deleted_at = now(), version = version + 1 WHERE id = $1", todoItemsTable)
+		_, err := tx.Exec(query, itemId)
+		return err
+	case todo.BulkMove:
+		query := fmt.Sprintf("UPDATE %s SET list_id = $1 WHERE item_id = $2", listsItemsTable)
+		if _, err := tx.Exec(query, *input.ListId, itemId); err != nil {
+			return err
+		}
+		return bumpItemVersion(tx, itemId)
+	case todo.BulkTag:
+		if err := changeItemTags(tx, itemId, input.AddTags, input.RemoveTags); err != nil {
+			return err
+		}
+		return bumpItemVersion(tx, itemId)
+	}
+
+	return fmt.Errorf("unknown bulk operation %q", input.Operation)
+}
+
+// lockWritableItem locks an item the user can access, rejecting items of
+// archived lists.
+func lockWritableItem(tx Tx, userId, itemId int) error {
+	var archived bool
+	query := fmt.Sprintf(`SELECT tl.archived_at IS NOT NULL FROM %s ti INNER JOIN %s li on li.item_id = ti.id
+							INNER JOIN %s ul on ul.list_id = li.list_id INNER JOIN %s tl on tl.id = li.list_id
+							WHERE ul.user_id = $1 AND ti.id = $2 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL FOR UPDATE OF ti`,
+		todoItemsTable, listsItemsTable, usersListsTable, todoListsTable)
+	if err := tx.QueryRow(query, userId, itemId).Scan(&archived); err != nil {
+		return translateError(err)
+	}
+	if archived {
+		return todo.ErrListArchived
+	}
+
+	return nil
+}
+
+// lockWritableList locks a list the user can access, rejecting archived
+// lists.
+func lockWritableList(tx Tx, userId, listId int) error {
+	var archived bool
+	query := fmt.Sprintf(`SELECT tl.archived_at IS NOT NULL FROM %s tl INNER JOIN %s ul on ul.list_id = tl.id
+							WHERE ul.user_id = $1 AND tl.id = $2 AND tl.deleted_at IS NULL FOR UPDATE OF tl`,
+		todoListsTable, usersListsTable)
+	if err := tx.QueryRow(query, userId, listId).Scan(&archived); err != nil {
+		return translateError(err)
+	}
+	if archived {
+		return todo.ErrListArchived
+	}
+
+	return nil
+}
+
+func bumpItemVersion(tx Tx, itemId int) error {
+	query := fmt.Sprintf("UPDATE %s SET version = version + 1 WHERE id = $1", todoItemsTable)
+	_, err := tx.Exec(query, itemId)
+
+	return err
+}
+
+// changeItemTags adds and removes tags of an item, keeping the others.
+func changeItemTags(tx Tx, itemId int, add, remove []string) error {
+	if len(remove) > 0 {
+		query := fmt.Sprintf("DELETE FROM %s WHERE item_id = $1 AND tag = ANY($2)", itemTagsTable)
+		if _, err := tx.Exec(query, itemId, pq.Array(remove)); err != nil {
+			return err
+		}
+	}
+
+	if len(add) > 0 {
+		query := fmt.Sprintf("INSERT INTO %s (item_id, tag) SELECT $1, unnest($2::varchar[]) ON CONFLICT DO NOTHING", itemTagsTable)
+		if _, err := tx.Exec(query, itemId, pq.Array(add)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
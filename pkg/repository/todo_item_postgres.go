@@ -2,52 +2,102 @@ package repository
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/MyNameIsWhaaat/todo-app"
-	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
 
+// itemColumns selects a todo item aliased as ti, joined to lists_items as li,
+// together with its tags.
+var itemColumns = fmt.Sprintf(`ti.id, li.list_id, ti.title, ti.description, ti.done, ti.due_date, ti.priority, ti.recurrence, ti.version, ti.created_at,
+	COALESCE((SELECT json_agg(it.tag ORDER BY it.tag) FROM %s it WHERE it.item_id = ti.id), '[]') AS tags`, itemTagsTable)
+
 type TodoItemPostgres struct {
-	db *sqlx.DB
+	db DB
 }
-func NewTodoItemPostgres(db *sqlx.DB) *TodoItemPostgres {
+func NewTodoItemPostgres(db DB) *TodoItemPostgres {
 	return &TodoItemPostgres{db: db}
 }
 
-func (r *TodoItemPostgres) Create(listId int, item todo.TodoItem) (int, error){
+// Create inserts the item into the list and returns it as stored, with the
+// fields the database fills in.
+func (r *TodoItemPostgres) Create(listId int, item todo.TodoItem) (todo.TodoItem, error){
 	tx, err := r.db.Begin()
     if err != nil { 
-        return 0, err
+        return todo.TodoItem{}, err
     }
 
-	var itemId int
-	createItemQuery := fmt.Sprintf("INSERT INTO %s (title, description) values ($1, $2) RETURNING id", todoItemsTable)
+	var created todo.TodoItem
+	createItemQuery := fmt.Sprintf(`INSERT INTO %s (title, description, due_date, priority, recurrence, search_language)
+									values ($1, $2, $3, $4, $5, (SELECT search_language FROM %s WHERE id = $6))
+									RETURNING id, title, description, done, due_date, priority, recurrence, version, created_at`, todoItemsTable, todoListsTable)
 
-	row := tx.QueryRow(createItemQuery, item.Title, item.Description)
-	err = row.Scan(&itemId)
+	row := tx.QueryRowx(createItemQuery, item.Title, item.Description, item.DueDate, item.Priority, item.Recurrence, listId)
+	err = row.StructScan(&created)
 	if err!=nil{
 		tx.Rollback()
-		return 0, err
+		return todo.TodoItem{}, err
 	}
 
 	createListItemsQuery := fmt.Sprintf("INSERT INTO %s (list_id, item_id) values ($1, $2)", listsItemsTable)
-	_, err = tx.Exec(createListItemsQuery, listId, itemId)
+	_, err = tx.Exec(createListItemsQuery, listId, created.Id)
 	if err != nil{
 		tx.Rollback()
-		return 0, err
+		return todo.TodoItem{}, err
+	}
+
+	if err := setItemTags(tx, created.Id, item.Tags); err != nil{
+		tx.Rollback()
+		return todo.TodoItem{}, err
 	}
 
-	return itemId, tx.Commit()
+	created.ListId = listId
+	created.Tags = append(todo.Tags{}, item.Tags...)
+	sort.Strings(created.Tags)
+
+	return created, tx.Commit()
 }
 
-func (r *TodoItemPostgres) GetAll(userId, listId int) ([]todo.TodoItem, error) {
+func (r *TodoItemPostgres) GetAll(userId, listId int, filter todo.ItemFilter, page todo.Page) ([]todo.TodoItem, error) {
 	var items []todo.TodoItem
-	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done FROM %s ti INNER JOIN %s li on li.item_id = ti.id
-									INNER JOIN %s ul on ul.list_id = li.list_id WHERE li.list_id = $2 AND ul.user_id = $1`,
-		todoItemsTable, listsItemsTable, usersListsTable)
-	if err := r.db.Select(&items, query, listId, userId); err != nil {
+	conditions := make([]string, 0)
+	args := []interface{}{userId, listId, page.AfterId, limitArg(page)}
+	argId := len(args) + 1
+
+	if filter.Done != nil {
+		conditions = append(conditions, fmt.Sprintf(" AND ti.done = $%d", argId))
+		args = append(args, *filter.Done)
+		argId++
+	}
+
+	if filter.DueBefore != nil {
+		conditions = append(conditions, fmt.Sprintf(" AND ti.due_date < $%d", argId))
+		args = append(args, *filter.DueBefore)
+		argId++
+	}
+
+	if filter.DueAfter != nil {
+		conditions = append(conditions, fmt.Sprintf(" AND ti.due_date > $%d", argId))
+		args = append(args, *filter.DueAfter)
+		argId++
+	}
+
+	if filter.Query != "" {
+		conditions = append(conditions, fmt.Sprintf(" AND (ti.title ILIKE $%d OR ti.description ILIKE $%d)", argId, argId))
+		args = append(args, "%"+likeEscaper.Replace(filter.Query)+"%")
+		argId++
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM %s ti INNER JOIN %s li on li.item_id = ti.id
+									INNER JOIN %s ul on ul.list_id = li.list_id INNER JOIN %s tl on tl.id = li.list_id
+									WHERE li.list_id = $2 AND ul.user_id = $1 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL AND ti.id > $3%s
+									ORDER BY ti.id LIMIT $4`,
+		itemColumns, todoItemsTable, listsItemsTable, usersListsTable, todoListsTable, strings.Join(conditions, ""))
+	if err := r.db.Select(&items, query, args...); err != nil {
 		return nil, err
 	}
 
@@ -56,17 +106,216 @@ func (r *TodoItemPostgres) GetAll(userId, listId int) ([]todo.TodoItem, error) {
 
 func (r *TodoItemPostgres) GetById(userId int, itemId int) (todo.TodoItem, error){
 	var item todo.TodoItem
-	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done FROM %s ti INNER JOIN %s li on li.item_id = ti.id
-							 INNER JOIN %s ul on ul.list_id = li.list_id WHERE ti.id = $1 AND ul.user_id = $2`, todoItemsTable, listsItemsTable, usersListsTable)
+	query := fmt.Sprintf(`SELECT %s FROM %s ti INNER JOIN %s li on li.item_id = ti.id
+							 INNER JOIN %s ul on ul.list_id = li.list_id INNER JOIN %s tl on tl.id = li.list_id
+							 WHERE ti.id = $1 AND ul.user_id = $2 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL`,
+							 itemColumns, todoItemsTable, listsItemsTable, usersListsTable, todoListsTable)
 	logrus.Infof("Executing query: %s with list_id=%d", query, itemId)
 
 	if err := r.db.Get(&item, query, itemId, userId); err!=nil{
-		return item, err
+		return item, translateError(err)
 	}
 	return item, nil
 }
 
-func (r *TodoItemPostgres) Update(userId, itemId int, input todo.UpdateItemInput) error{
+// GetForUpdate returns the item like GetById and locks its row until the
+// surrounding transaction ends, so that it cannot change before the caller
+// writes it.
+func (r *TodoItemPostgres) GetForUpdate(userId int, itemId int) (todo.TodoItem, error){
+	var item todo.TodoItem
+	query := fmt.Sprintf(`SELECT %s FROM %s ti INNER JOIN %s li on li.item_id = ti.id
+							 INNER JOIN %s ul on ul.list_id = li.list_id INNER JOIN %s tl on tl.id = li.list_id
+							 WHERE ti.id = $1 AND ul.user_id = $2 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL
+							 FOR UPDATE OF ti`,
+							 itemColumns, todoItemsTable, listsItemsTable, usersListsTable, todoListsTable)
+
+	if err := r.db.Get(&item, query, itemId, userId); err!=nil{
+		return item, translateError(err)
+	}
+	return item, nil
+}
+
+// GetByLists returns the items of all the given lists at once, ordered by
+// list.
+func (r *TodoItemPostgres) GetByLists(userId int, listIds []int) ([]todo.TodoItem, error){
+	var items []todo.TodoItem
+	query := fmt.Sprintf(`SELECT %s FROM %s ti INNER JOIN %s li on li.item_id = ti.id
+							 INNER JOIN %s ul on ul.list_id = li.list_id INNER JOIN %s tl on tl.id = li.list_id
+							 WHERE li.list_id = ANY($2) AND ul.user_id = $1 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL
+							 ORDER BY li.list_id, ti.id`,
+							 itemColumns, todoItemsTable, listsItemsTable, usersListsTable, todoListsTable)
+	err := r.db.Select(&items, query, userId, pq.Array(listIds))
+
+	return items, err
+}
+
+func (r *TodoItemPostgres) GetListId(userId, itemId int) (int, error){
+	var listId int
+	query := fmt.Sprintf(`SELECT li.list_id FROM %s li INNER JOIN %s ul on ul.list_id = li.list_id INNER JOIN %s ti on ti.id = li.item_id
+							WHERE li.item_id = $1 AND ul.user_id = $2 AND ti.deleted_at IS NULL`, listsItemsTable, usersListsTable, todoItemsTable)
+	err := r.db.Get(&listId, query, itemId, userId)
+
+	return listId, translateError(err)
+}
+
+// GetByIds returns those of the items the user has access to.
+func (r *TodoItemPostgres) GetByIds(userId int, itemIds []int) ([]todo.TodoItem, error){
+	var items []todo.TodoItem
+	query := fmt.Sprintf(`SELECT %s FROM %s ti INNER JOIN %s li on li.item_id = ti.id
+							 INNER JOIN %s ul on ul.list_id = li.list_id INNER JOIN %s tl on tl.id = li.list_id
+							 WHERE ti.id = ANY($2) AND ul.user_id = $1 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL
+							 ORDER BY ti.id`,
+							 itemColumns, todoItemsTable, listsItemsTable, usersListsTable, todoListsTable)
+	err := r.db.Select(&items, query, userId, pq.Array(itemIds))
+
+	return items, err
+}
+
+// GetByIdsForUpdate returns the items like GetByIds and locks their rows,
+// in id order, until the surrounding transaction ends.
+func (r *TodoItemPostgres) GetByIdsForUpdate(userId int, itemIds []int) ([]todo.TodoItem, error){
+	var items []todo.TodoItem
+	query := fmt.Sprintf(`SELECT %s FROM %s ti INNER JOIN %s li on li.item_id = ti.id
+							 INNER JOIN %s ul on ul.list_id = li.list_id INNER JOIN %s tl on tl.id = li.list_id
+							 WHERE ti.id = ANY($2) AND ul.user_id = $1 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL
+							 ORDER BY ti.id FOR UPDATE OF ti`,
+							 itemColumns, todoItemsTable, listsItemsTable, usersListsTable, todoListsTable)
+	err := r.db.Select(&items, query, userId, pq.Array(itemIds))
+
+	return items, err
+}
+
+// GetListIds maps those of the items the user has access to onto their
+// lists.
+func (r *TodoItemPostgres) GetListIds(userId int, itemIds []int) (map[int]int, error){
+	var rows []struct{
+		ItemId int `db:"item_id"`
+		ListId int `db:"list_id"`
+	}
+	query := fmt.Sprintf(`SELECT li.item_id, li.list_id FROM %s li INNER JOIN %s ul on ul.list_id = li.list_id
+							WHERE li.item_id = ANY($1) AND ul.user_id = $2`, listsItemsTable, usersListsTable)
+	if err := r.db.Select(&rows, query, pq.Array(itemIds), userId); err != nil{
+		return nil, err
+	}
+
+	listIds := make(map[int]int, len(rows))
+	for _, row := range rows{
+		listIds[row.ItemId] = row.ListId
+	}
+
+	return listIds, nil
+}
+
+func (r *TodoItemPostgres) Update(userId, itemId int, input todo.UpdateItemInput, version int) error{
+    tx, err := r.db.Begin()
+    if err != nil{
+        return err
+    }
+
+    if err := updateItem(tx, userId, itemId, input, version); err != nil{
+        tx.Rollback()
+        return err
+    }
+
+    return tx.Commit()
+}
+
+func (r *TodoItemPostgres) Delete(userId, itemId int, version int) error {
+	query := fmt.Sprintf(`UPDATE %s ti SET deleted_at = now(), version = ti.version + 1 FROM %s li, %s ul
+							WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $1 AND ti.id = $2 AND ti.deleted_at IS NULL
+							AND ($3 = 0 OR ti.version = $3)`,
+							todoItemsTable, listsItemsTable, usersListsTable)
+	res, err := r.db.Exec(query, userId, itemId, version)
+	return checkVersioned(res, err, version, func() error {
+		_, err := r.GetListId(userId, itemId)
+		return err
+	})
+}
+
+// GetByFilter evaluates a saved filter query against the items of every
+// active list the user belongs to.
+func (r *TodoItemPostgres) GetByFilter(userId int, filter todo.FilterQuery, page todo.Page) ([]todo.TodoItem, error) {
+	var items []todo.TodoItem
+	conditions := make([]string, 0)
+	args := []interface{}{userId, page.AfterId, limitArg(page)}
+	argId := len(args) + 1
+
+	if filter.Done != nil {
+		conditions = append(conditions, fmt.Sprintf(" AND ti.done = $%d", argId))
+		args = append(args, *filter.Done)
+		argId++
+	}
+
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, fmt.Sprintf(" AND EXISTS (SELECT 1 FROM %s it WHERE it.item_id = ti.id AND it.tag = ANY($%d))", itemTagsTable, argId))
+		args = append(args, pq.Array(filter.Tags))
+		argId++
+	}
+
+	if filter.PriorityMin != nil {
+		conditions = append(conditions, fmt.Sprintf(" AND ti.priority >= $%d", argId))
+		args = append(args, *filter.PriorityMin)
+		argId++
+	}
+
+	if filter.PriorityMax != nil {
+		conditions = append(conditions, fmt.Sprintf(" AND ti.priority <= $%d", argId))
+		args = append(args, *filter.PriorityMax)
+		argId++
+	}
+
+	if filter.DueBefore != nil {
+		conditions = append(conditions, fmt.Sprintf(" AND ti.due_date < $%d", argId))
+		args = append(args, *filter.DueBefore)
+		argId++
+	}
+
+	if filter.DueAfter != nil {
+		conditions = append(conditions, fmt.Sprintf(" AND ti.due_date > $%d", argId))
+		args = append(args, *filter.DueAfter)
+		argId++
+	}
+
+	if len(filter.ListIds) > 0 {
+		conditions = append(conditions, fmt.Sprintf(" AND li.list_id = ANY($%d)", argId))
+		args = append(args, pq.Array(filter.ListIds))
+		argId++
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM %s ti INNER JOIN %s li on li.item_id = ti.id
+									INNER JOIN %s ul on ul.list_id = li.list_id INNER JOIN %s tl on tl.id = li.list_id
+									WHERE ul.user_id = $1 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL AND tl.archived_at IS NULL AND ti.id > $2%s
+									ORDER BY ti.id LIMIT $3`,
+		itemColumns, todoItemsTable, listsItemsTable, usersListsTable, todoListsTable, strings.Join(conditions, ""))
+	if err := r.db.Select(&items, query, args...); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// GetDueBetween returns the open items of the user's active lists due in
+// [from, to), earliest first. A nil bound leaves that side of the range open.
+func (r *TodoItemPostgres) GetDueBetween(userId int, from, to *time.Time) ([]todo.TodoItem, error) {
+	var items []todo.TodoItem
+	query := fmt.Sprintf(`SELECT %s FROM %s ti INNER JOIN %s li on li.item_id = ti.id
+									INNER JOIN %s ul on ul.list_id = li.list_id INNER JOIN %s tl on tl.id = li.list_id
+									WHERE ul.user_id = $1 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL AND tl.archived_at IS NULL
+									AND NOT ti.done AND ti.due_date IS NOT NULL
+									AND ($2::timestamptz IS NULL OR ti.due_date >= $2) AND ($3::timestamptz IS NULL OR ti.due_date < $3)
+									ORDER BY ti.due_date, ti.id`,
+		itemColumns, todoItemsTable, listsItemsTable, usersListsTable, todoListsTable)
+	if err := r.db.Select(&items, query, userId, from, to); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// updateItem applies the patch to an item the user can access within tx and
+// bumps its version. A non-zero version makes the change conditional on the
+// item still being at that version.
+func updateItem(tx Tx, userId, itemId int, input todo.UpdateItemInput, version int) error{
     setValues := make([]string, 0)
     args := make([]interface{}, 0)
     argId := 1
@@ -77,9 +326,9 @@ func (r *TodoItemPostgres) Update(userId, itemId int, input todo.UpdateItemInput
         argId++
     }
 
-    if input.Description != nil{
+    if input.Description.Set{
         setValues = append(setValues, fmt.Sprintf("description=$%d", argId))
-        args = append(args, *input.Description)
+        args = append(args, input.Description.Value)
         argId++
     }
 
@@ -89,23 +338,70 @@ func (r *TodoItemPostgres) Update(userId, itemId int, input todo.UpdateItemInput
         argId++
     }
 
+    if input.DueDate.Set{
+        setValues = append(setValues, fmt.Sprintf("due_date=$%d", argId))
+        args = append(args, input.DueDate.Value)
+        argId++
+    }
+
+    if input.Priority != nil{
+        setValues = append(setValues, fmt.Sprintf("priority=$%d", argId))
+        args = append(args, *input.Priority)
+        argId++
+    }
+
+    if input.Recurrence != nil{
+        setValues = append(setValues, fmt.Sprintf("recurrence=$%d", argId))
+        args = append(args, *input.Recurrence)
+        argId++
+    }
+
+    setValues = append(setValues, "version=ti.version + 1")
     setQuery := strings.Join(setValues, " ,")
 
     query := fmt.Sprintf(`UPDATE %s ti SET %s FROM %s li, %s ul
-							WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $%d AND ti.id = $%d`,
-        todoItemsTable, setQuery, listsItemsTable, usersListsTable, argId, argId + 1)
+							WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $%d AND ti.id = $%d AND ti.deleted_at IS NULL
+							AND ($%d = 0 OR ti.version = $%d)`,
+        todoItemsTable, setQuery, listsItemsTable, usersListsTable, argId, argId + 1, argId + 2, argId + 2)
+
+    args = append(args, userId, itemId, version)
 
-    args = append(args, userId, itemId)
+    res, err := tx.Exec(query, args...)
+    if err := checkVersioned(res, err, version, func() error { return lockItem(tx, userId, itemId) }); err != nil{
+        return err
+    }
 
-    _, err := r.db.Exec(query, args...)
+    if input.Tags != nil{
+        return setItemTags(tx, itemId, *input.Tags)
+    }
 
-    return err
+    return nil
 }
 
-func (r *TodoItemPostgres) Delete(userId, itemId int) error {
-	query := fmt.Sprintf(`DELETE FROM %s ti USING %s li, %s ul 
-							WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $1 AND ti.id = $2`,
-							todoItemsTable, listsItemsTable, usersListsTable)
-	_, err := r.db.Exec(query, userId, itemId)
+// lockItem checks that the user can access the item and locks it for the
+// rest of the transaction.
+func lockItem(tx Tx, userId, itemId int) error {
+	var id int
+	query := fmt.Sprintf(`SELECT ti.id FROM %s ti INNER JOIN %s li on li.item_id = ti.id INNER JOIN %s ul on ul.list_id = li.list_id
+							WHERE ul.user_id = $1 AND ti.id = $2 AND ti.deleted_at IS NULL FOR UPDATE OF ti`,
+		todoItemsTable, listsItemsTable, usersListsTable)
+
+	return translateError(tx.QueryRow(query, userId, itemId).Scan(&id))
+}
+
+// setItemTags replaces the tags of an item.
+func setItemTags(tx Tx, itemId int, tags []string) error {
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE item_id = $1", itemTagsTable)
+	if _, err := tx.Exec(deleteQuery, itemId); err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (item_id, tag) SELECT $1, unnest($2::varchar[])", itemTagsTable)
+	_, err := tx.Exec(insertQuery, itemId, pq.Array(tags))
+
 	return err
-}
\ No newline at end of file
+}
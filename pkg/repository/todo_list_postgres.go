@@ -5,72 +5,104 @@ import (
 	"strings"
 
 	"github.com/MyNameIsWhaaat/todo-app"
-	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
 
 type TodoListPostgres struct {
-	db *sqlx.DB
+	db DB
 }
 
-func NewTodoListPostgres(db *sqlx.DB) *TodoListPostgres {
+func NewTodoListPostgres(db DB) *TodoListPostgres {
 	return &TodoListPostgres{db: db}
 }
 
-func (r *TodoListPostgres) Create(userId int, list todo.TodoList) (int, error){
-	logrus.Infof("Create() called with userId: %d, list title: %s, description: %s", userId, list.Title, list.Description)
+// Create inserts the list and returns it as stored, with the fields the
+// database fills in.
+func (r *TodoListPostgres) Create(userId int, list todo.TodoList) (todo.TodoList, error){
+	logrus.Infof("Create() called with userId: %d, list title: %s", userId, list.Title)
 
     tx, err := r.db.Begin()
     if err != nil {
         logrus.Errorf("failed to begin transaction: %s", err.Error())
-        return 0, err
+        return todo.TodoList{}, err
     }
 
-    var id int
-    createListQuery := fmt.Sprintf("INSERT INTO %s (title, description) VALUES ($1, $2) RETURNING id", todoListsTable)
-    row := tx.QueryRow(createListQuery, list.Title, list.Description)
-    if err := row.Scan(&id); err != nil {
+    var created todo.TodoList
+    createListQuery := fmt.Sprintf(`INSERT INTO %s (title, description, search_language)
+                                    VALUES ($1, $2, (SELECT search_language FROM %s WHERE id = $3))
+                                    RETURNING id, title, description, archived_at, version, created_at`, todoListsTable, usersTable)
+    row := tx.QueryRowx(createListQuery, list.Title, list.Description, userId)
+    if err := row.StructScan(&created); err != nil {
         tx.Rollback()
-        logrus.Errorf("failed to scan id: %s", err.Error())
-        return 0, err
+        logrus.Errorf("failed to scan list: %s", err.Error())
+        return todo.TodoList{}, err
     }
 
     createUsersListQuery := fmt.Sprintf("INSERT INTO %s (user_id, list_id) VALUES ($1, $2)", usersListsTable)
-    _, err = tx.Exec(createUsersListQuery, userId, id)
+    _, err = tx.Exec(createUsersListQuery, userId, created.Id)
     if err != nil {
         tx.Rollback()
         logrus.Errorf("failed to execute users list query: %s", err.Error())
-        return 0, err
+        return todo.TodoList{}, err
     }
 
     if err := tx.Commit(); err != nil {
 		logrus.Errorf("failed to commit transaction: %s", err.Error())
-		return 0, err
+		return todo.TodoList{}, err
 	}
-	logrus.Info("Transaction committed successfully, ID:", id)
+	logrus.Info("Transaction committed successfully, ID:", created.Id)
 
-    return id, nil
+    return created, nil
 }
 
-func (r *TodoListPostgres) 	GetAll(userId int) ([]todo.TodoList, error){
+func (r *TodoListPostgres) 	GetAll(userId int, archived bool, page todo.Page) ([]todo.TodoList, error){
     var lists []todo.TodoList
-    query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description FROM %s tl INNER JOIN %s ul on tl.id = ul.list_id WHERE ul.user_id = $1",
+    query := fmt.Sprintf(`SELECT tl.id, tl.title, tl.description, tl.archived_at, tl.version, tl.created_at FROM %s tl INNER JOIN %s ul on tl.id = ul.list_id
+                            WHERE ul.user_id = $1 AND tl.deleted_at IS NULL AND (tl.archived_at IS NOT NULL) = $2 AND tl.id > $3
+                            ORDER BY tl.id LIMIT $4`,
         todoListsTable, usersListsTable)
-    err := r.db.Select(&lists, query, userId)
+    err := r.db.Select(&lists, query, userId, archived, page.AfterId, limitArg(page))
 
     return lists, err
 }
 
 func (r *TodoListPostgres) 	GetById(userId, listId int) (todo.TodoList, error){
     var list todo.TodoList
-    query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description FROM %s tl INNER JOIN %s ul on tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = $2",
+    query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description, tl.archived_at, tl.version, tl.created_at FROM %s tl INNER JOIN %s ul on tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = $2 AND tl.deleted_at IS NULL",
         todoListsTable, usersListsTable)
     err := r.db.Get(&list, query, userId, listId)
 
-    return list, err
+    return list, translateError(err)
+}
+
+func (r *TodoListPostgres) GetByIds(userId int, listIds []int) ([]todo.TodoList, error){
+    var lists []todo.TodoList
+    query := fmt.Sprintf(`SELECT tl.id, tl.title, tl.description, tl.archived_at, tl.version, tl.created_at FROM %s tl INNER JOIN %s ul on tl.id = ul.list_id
+                            WHERE ul.user_id = $1 AND ul.list_id = ANY($2) AND tl.deleted_at IS NULL ORDER BY tl.id`,
+        todoListsTable, usersListsTable)
+    err := r.db.Select(&lists, query, userId, pq.Array(listIds))
+
+    return lists, err
+}
+
+// GetMembers returns the users with access to those of the lists the user
+// has access to and that are not in the trash, ordered by list.
+func (r *TodoListPostgres) GetMembers(userId int, listIds []int) ([]todo.ListMember, error){
+    var members []todo.ListMember
+    query := fmt.Sprintf(`SELECT ul.list_id, u.id, u.name, u.username FROM %s ul INNER JOIN %s u on u.id = ul.user_id
+                            INNER JOIN %s tl on tl.id = ul.list_id
+                            WHERE ul.list_id = ANY($2) AND ul.list_id IN (SELECT list_id FROM %s WHERE user_id = $1) AND tl.deleted_at IS NULL
+                            ORDER BY ul.list_id, u.id`,
+        usersListsTable, usersTable, todoListsTable, usersListsTable)
+    err := r.db.Select(&members, query, userId, pq.Array(listIds))
+
+    return members, err
 }
 
-func (r *TodoListPostgres) Update(userId, listId int, input todo.UpdateListInput) error{
+// Update changes the list and bumps its version. A non-zero version makes
+// the change conditional on the list still being at that version.
+func (r *TodoListPostgres) Update(userId, listId int, input todo.UpdateListInput, version int) error{
     setValues := make([]string, 0)
     args := make([]interface{}, 0)
     argId := 1
@@ -81,32 +113,99 @@ func (r *TodoListPostgres) Update(userId, listId int, input todo.UpdateListInput
         argId++
     }
 
-    if input.Description != nil{
+    if input.Description.Set{
         setValues = append(setValues, fmt.Sprintf("description=$%d", argId))
-        args = append(args, *input.Description)
+        args = append(args, input.Description.Value)
         argId++
     }
 
+    setValues = append(setValues, "version=tl.version + 1")
     setQuery := strings.Join(setValues, " ,")
 
-    query := fmt.Sprintf("UPDATE %s tl SET %s FROM %s ul WHERE tl.id = ul.list_id AND ul.list_id = $%d AND ul.user_id = $%d",
-        todoListsTable, setQuery, usersListsTable, argId, argId + 1)
+    query := fmt.Sprintf(`UPDATE %s tl SET %s FROM %s ul WHERE tl.id = ul.list_id AND ul.list_id = $%d AND ul.user_id = $%d AND tl.deleted_at IS NULL
+                            AND ($%d = 0 OR tl.version = $%d)`,
+        todoListsTable, setQuery, usersListsTable, argId, argId + 1, argId + 2, argId + 2)
 
-    args = append(args, listId, userId)
+    args = append(args, listId, userId, version)
 
     logrus.Debugf("updateQuery: %s", query)
     logrus.Debugf("args: %s", args)
 
-    _, err := r.db.Exec(query, args...)
+    res, err := r.db.Exec(query, args...)
+    return checkVersioned(res, err, version, func() error {
+        _, err := r.GetById(userId, listId)
+        return err
+    })
+}
+
+func (r *TodoListPostgres) 	Delete(userId, listId int, version int) error{
+    query := fmt.Sprintf(`UPDATE %s tl SET deleted_at = now(), version = tl.version + 1 FROM %s ul WHERE tl.id = ul.list_id AND ul.user_id = $1 AND ul.list_id = $2 AND tl.deleted_at IS NULL
+                            AND ($3 = 0 OR tl.version = $3)`,
+        todoListsTable, usersListsTable)
+
+    res, err := r.db.Exec(query, userId, listId, version)
+    return checkVersioned(res, err, version, func() error {
+        _, err := r.GetById(userId, listId)
+        return err
+    })
+}
+
+func (r *TodoListPostgres) Archive(userId, listId int) error{
+    query := fmt.Sprintf("UPDATE %s tl SET archived_at = COALESCE(tl.archived_at, now()), version = tl.version + 1 FROM %s ul WHERE tl.id = ul.list_id AND ul.user_id = $1 AND ul.list_id = $2 AND tl.deleted_at IS NULL",
+        todoListsTable, usersListsTable)
 
-    return err
+    return checkAffected(r.db.Exec(query, userId, listId))
 }
 
-func (r *TodoListPostgres) 	Delete(userId, listId int) error{
-    query := fmt.Sprintf("DELETE FROM %s tl USING %s ul WHERE tl.id = ul.list_id AND ul.user_id = $1 AND ul.list_id = $2",
+func (r *TodoListPostgres) Unarchive(userId, listId int) error{
+    query := fmt.Sprintf("UPDATE %s tl SET archived_at = NULL, version = tl.version + 1 FROM %s ul WHERE tl.id = ul.list_id AND ul.user_id = $1 AND ul.list_id = $2 AND tl.deleted_at IS NULL",
         todoListsTable, usersListsTable)
-    _, err := r.db.Exec(query, userId, listId)
 
-    return err
+    return checkAffected(r.db.Exec(query, userId, listId))
 }
 
+func (r *TodoListPostgres) CreateWithItems(userId int, list todo.TodoList, items []todo.TodoItem) (int, error){
+    tx, err := r.db.Begin()
+    if err != nil {
+        return 0, err
+    }
+
+    var id int
+    createListQuery := fmt.Sprintf(`INSERT INTO %s (title, description, search_language)
+                                    VALUES ($1, $2, (SELECT search_language FROM %s WHERE id = $3)) RETURNING id`, todoListsTable, usersTable)
+    row := tx.QueryRow(createListQuery, list.Title, list.Description, userId)
+    if err := row.Scan(&id); err != nil {
+        tx.Rollback()
+        return 0, err
+    }
+
+    createUsersListQuery := fmt.Sprintf("INSERT INTO %s (user_id, list_id) VALUES ($1, $2)", usersListsTable)
+    if _, err := tx.Exec(createUsersListQuery, userId, id); err != nil {
+        tx.Rollback()
+        return 0, err
+    }
+
+    createItemQuery := fmt.Sprintf(`INSERT INTO %s (title, description, done, due_date, priority, recurrence, search_language)
+                                    VALUES ($1, $2, $3, $4, $5, $6, (SELECT search_language FROM %s WHERE id = $7)) RETURNING id`, todoItemsTable, todoListsTable)
+    createListItemsQuery := fmt.Sprintf("INSERT INTO %s (list_id, item_id) VALUES ($1, $2)", listsItemsTable)
+    for _, item := range items {
+        var itemId int
+        row := tx.QueryRow(createItemQuery, item.Title, item.Description, item.Done, item.DueDate, item.Priority, item.Recurrence, id)
+        if err := row.Scan(&itemId); err != nil {
+            tx.Rollback()
+            return 0, err
+        }
+
+        if _, err := tx.Exec(createListItemsQuery, id, itemId); err != nil {
+            tx.Rollback()
+            return 0, err
+        }
+
+        if err := setItemTags(tx, itemId, item.Tags); err != nil {
+            tx.Rollback()
+            return 0, err
+        }
+    }
+
+    return id, tx.Commit()
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+)
+
+type TrashPostgres struct {
+	db DB
+}
+
+func NewTrashPostgres(db DB) *TrashPostgres {
+	return &TrashPostgres{db: db}
+}
+
+func (r *TrashPostgres) GetLists(userId int) ([]todo.TodoList, error) {
+	var lists []todo.TodoList
+	query := fmt.Sprintf(`SELECT tl.id, tl.title, tl.description, tl.archived_at, tl.version, tl.created_at, tl.deleted_at FROM %s tl INNER JOIN %s ul on tl.id = ul.list_id
+							WHERE ul.user_id = $1 AND tl.deleted_at IS NOT NULL ORDER BY tl.deleted_at DESC`,
+		todoListsTable, usersListsTable)
+	err := r.db.Select(&lists, query, userId)
+
+	return lists, err
+}
+
+// GetItems returns the deleted items that can be restored on their own,
+// leaving out those of deleted lists.
+func (r *TrashPostgres) GetItems(userId int) ([]todo.TodoItem, error) {
+	var items []todo.TodoItem
+	query := fmt.Sprintf(`SELECT %s, ti.deleted_at FROM %s ti INNER JOIN %s li on li.item_id = ti.id
+							INNER JOIN %s ul on ul.list_id = li.list_id INNER JOIN %s tl on tl.id = li.list_id
+							WHERE ul.user_id = $1 AND ti.deleted_at IS NOT NULL AND tl.deleted_at IS NULL ORDER BY ti.deleted_at DESC`,
+		itemColumns, todoItemsTable, listsItemsTable, usersListsTable, todoListsTable)
+	err := r.db.Select(&items, query, userId)
+
+	return items, err
+}
+
+func (r *TrashPostgres) RestoreList(userId, listId int) error {
+	query := fmt.Sprintf("UPDATE %s tl SET deleted_at = NULL, version = tl.version + 1 FROM %s ul WHERE tl.id = ul.list_id AND ul.user_id = $1 AND ul.list_id = $2 AND tl.deleted_at IS NOT NULL",
+		todoListsTable, usersListsTable)
+
+	return checkAffected(r.db.Exec(query, userId, listId))
+}
+
+// RestoreItem brings an item back only when its list is not in the trash
+// itself; such items come back together with the list.
+func (r *TrashPostgres) RestoreItem(userId, itemId int) error {
+	query := fmt.Sprintf(`UPDATE %s ti SET deleted_at = NULL, version = ti.version + 1 FROM %s li, %s ul, %s tl
+							WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND tl.id = li.list_id
+							AND ul.user_id = $1 AND ti.id = $2 AND ti.deleted_at IS NOT NULL AND tl.deleted_at IS NULL`,
+		todoItemsTable, listsItemsTable, usersListsTable, todoListsTable)
+
+	return checkAffected(r.db.Exec(query, userId, itemId))
+}
+
+// Purge permanently removes lists and items trashed before the given time,
+// including all items of purged lists, and returns the number of removed rows.
+func (r *TrashPostgres) Purge(before time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	queries := []string{
+		fmt.Sprintf(`DELETE FROM %s ti USING %s li, %s tl WHERE ti.id = li.item_id AND li.list_id = tl.id AND tl.deleted_at < $1`,
+			todoItemsTable, listsItemsTable, todoListsTable),
+		fmt.Sprintf("DELETE FROM %s WHERE deleted_at < $1", todoItemsTable),
+		fmt.Sprintf("DELETE FROM %s WHERE deleted_at < $1", todoListsTable),
+	}
+
+	var total int64
+	for _, query := range queries {
+		res, err := tx.Exec(query, before)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		count, err := res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		total += count
+	}
+
+	return total, tx.Commit()
+}
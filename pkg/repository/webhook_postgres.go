@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+)
+
+const webhookColumns = "id, url, secret, events, active, failure_count, disabled_at, created_at"
+
+const deliveryColumns = `d.id, d.webhook_id, d.event_type, d.payload, d.status, d.attempts, d.response_status,
+	d.last_error, d.next_attempt_at, d.last_attempt_at, d.created_at`
+
+type WebhookPostgres struct {
+	db DB
+}
+
+func NewWebhookPostgres(db DB) *WebhookPostgres {
+	return &WebhookPostgres{db: db}
+}
+
+func (r *WebhookPostgres) Create(userId int, input todo.WebhookInput) (int, error) {
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (user_id, url, secret, events) VALUES ($1, $2, $3, $4) RETURNING id", webhooksTable)
+	if err := r.db.QueryRow(query, userId, input.URL, input.Secret, input.Events).Scan(&id); err != nil {
+		return 0, translateError(err)
+	}
+
+	return id, nil
+}
+
+func (r *WebhookPostgres) GetAll(userId int) ([]todo.Webhook, error) {
+	var webhooks []todo.Webhook
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE user_id = $1 ORDER BY id", webhookColumns, webhooksTable)
+	err := r.db.Select(&webhooks, query, userId)
+
+	return webhooks, err
+}
+
+func (r *WebhookPostgres) GetById(userId, webhookId int) (todo.Webhook, error) {
+	var webhook todo.Webhook
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE user_id = $1 AND id = $2", webhookColumns, webhooksTable)
+	err := r.db.Get(&webhook, query, userId, webhookId)
+
+	return webhook, translateError(err)
+}
+
+func (r *WebhookPostgres) Update(userId, webhookId int, input todo.UpdateWebhookInput) error {
+	setValues := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if input.URL != nil {
+		setValues = append(setValues, fmt.Sprintf("url=$%d", argId))
+		args = append(args, *input.URL)
+		argId++
+	}
+
+	if input.Secret != nil {
+		setValues = append(setValues, fmt.Sprintf("secret=$%d", argId))
+		args = append(args, *input.Secret)
+		argId++
+	}
+
+	if input.Events != nil {
+		setValues = append(setValues, fmt.Sprintf("events=$%d", argId))
+		args = append(args, *input.Events)
+		argId++
+	}
+
+	if input.Active != nil {
+		setValues = append(setValues, fmt.Sprintf("active=$%d", argId))
+		args = append(args, *input.Active)
+		argId++
+
+		// Re-enabling starts the failure count over; disabling by hand is
+		// not a failure, so it leaves disabled_at empty.
+		if *input.Active {
+			setValues = append(setValues, "failure_count=0", "disabled_at=NULL")
+		}
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE user_id = $%d AND id = $%d",
+		webhooksTable, strings.Join(setValues, " ,"), argId, argId+1)
+	args = append(args, userId, webhookId)
+
+	if input.Active == nil || *input.Active {
+		return checkAffected(r.db.Exec(query, args...))
+	}
+
+	// The deliveries pending for a deactivated webhook are dropped, so
+	// that nothing more reaches the endpoint once its owner turned it off.
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := checkAffected(tx.Exec(query, args...)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := dropPendingDeliveries(tx, webhookId, "webhook deactivated"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *WebhookPostgres) Delete(userId, webhookId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND id = $2", webhooksTable)
+
+	return checkAffected(r.db.Exec(query, userId, webhookId))
+}
+
+// GetDeliveries returns the deliveries of the webhook, oldest first.
+func (r *WebhookPostgres) GetDeliveries(userId, webhookId int, page todo.Page) ([]todo.WebhookDelivery, error) {
+	if _, err := r.GetById(userId, webhookId); err != nil {
+		return nil, err
+	}
+
+	var deliveries []todo.WebhookDelivery
+	query := fmt.Sprintf(`SELECT %s FROM %s d WHERE d.webhook_id = $1 AND d.id > $2 ORDER BY d.id LIMIT $3`,
+		deliveryColumns, webhookDeliveriesTable)
+	err := r.db.Select(&deliveries, query, webhookId, page.AfterId, limitArg(page))
+
+	return deliveries, err
+}
+
+// Enqueue queues the event for every active webhook subscribed to it whose
+// owner is a member of the event's list. An event already queued for a
+// webhook is not queued again.
+func (r *WebhookPostgres) Enqueue(event todo.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (webhook_id, event_id, event_type, payload)
+							SELECT w.id, $1, $2::text, $3::jsonb FROM %s w INNER JOIN %s ul ON ul.user_id = w.user_id
+							WHERE ul.list_id = $4 AND w.active AND w.events @> jsonb_build_array($2::text)
+							ON CONFLICT (webhook_id, event_id) DO NOTHING`,
+		webhookDeliveriesTable, webhooksTable, usersListsTable)
+	_, err = r.db.Exec(query, event.Id, event.Type, string(payload), event.ListId)
+
+	return err
+}
+
+// ClaimDue returns up to limit pending deliveries of active webhooks that
+// are due and pushes their next attempt back by lease, so that other
+// workers skip them while they are being sent. A delivery whose worker dies
+// is retried once the lease runs out.
+func (r *WebhookPostgres) ClaimDue(limit int, lease time.Duration) ([]todo.WebhookDispatch, error) {
+	var dispatches []todo.WebhookDispatch
+	query := fmt.Sprintf(`UPDATE %[1]s d SET next_attempt_at = now() + $1 * interval '1 millisecond'
+							FROM %[2]s w
+							WHERE w.id = d.webhook_id AND w.active AND d.id IN (
+								SELECT dd.id FROM %[1]s dd INNER JOIN %[2]s ww ON ww.id = dd.webhook_id
+								WHERE dd.status = $2 AND dd.next_attempt_at <= now() AND ww.active
+								ORDER BY dd.next_attempt_at LIMIT $3 FOR UPDATE OF dd SKIP LOCKED)
+							RETURNING %[3]s, w.url, w.secret`,
+		webhookDeliveriesTable, webhooksTable, deliveryColumns)
+	err := r.db.Select(&dispatches, query, lease.Milliseconds(), todo.DeliveryPending, limit)
+
+	return dispatches, err
+}
+
+// RecordAttempt stores the outcome of sending a delivery and returns how
+// many deliveries of its webhook have failed in a row since. It fails with
+// todo.ErrPreconditionFailed when the delivery is no longer held by the
+// attempt's lease, because the lease ran out and another worker claimed it
+// or because it was dropped.
+func (r *WebhookPostgres) RecordAttempt(attempt todo.WebhookAttempt) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	updateDeliveryQuery := fmt.Sprintf(`UPDATE %s SET status = $1, attempts = attempts + 1, response_status = $2,
+										last_error = $3, next_attempt_at = $4, last_attempt_at = now()
+										WHERE id = $5 AND status = $6 AND next_attempt_at = $7`,
+		webhookDeliveriesTable)
+	err = checkAffected(tx.Exec(updateDeliveryQuery, attempt.Status, attempt.ResponseStatus, attempt.Error, attempt.NextAttemptAt,
+		attempt.DeliveryId, todo.DeliveryPending, attempt.Lease))
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, todo.ErrNotFound) {
+			return 0, todo.ErrPreconditionFailed
+		}
+		return 0, err
+	}
+
+	var failures int
+	updateWebhookQuery := fmt.Sprintf(`UPDATE %s SET failure_count = CASE WHEN $1 THEN 0 ELSE failure_count + 1 END
+										WHERE id = $2 RETURNING failure_count`, webhooksTable)
+	err = tx.QueryRow(updateWebhookQuery, attempt.Status == todo.DeliverySucceeded, attempt.WebhookId).Scan(&failures)
+	if err != nil {
+		tx.Rollback()
+		return 0, translateError(err)
+	}
+
+	return failures, tx.Commit()
+}
+
+// Disable stops queueing events for the webhook and drops the deliveries
+// still pending for it.
+func (r *WebhookPostgres) Disable(webhookId int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET active = false, disabled_at = now() WHERE id = $1 AND active", webhooksTable)
+	if _, err := tx.Exec(query, webhookId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := dropPendingDeliveries(tx, webhookId, "webhook disabled"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// dropPendingDeliveries fails the deliveries still pending for the webhook
+// with reason, so that they are never sent.
+func dropPendingDeliveries(tx Tx, webhookId int, reason string) error {
+	query := fmt.Sprintf(`UPDATE %s SET status = $1, next_attempt_at = NULL, last_error = $2
+						WHERE webhook_id = $3 AND status = $4`, webhookDeliveriesTable)
+	_, err := tx.Exec(query, todo.DeliveryFailed, reason, webhookId, todo.DeliveryPending)
+
+	return err
+}
@@ -0,0 +1,163 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/rpc/todov1"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationMetadata = "authorization"
+	requestIdMetadata     = "x-request-id"
+)
+
+type userKey struct{}
+
+// authenticator checks the bearer token of every call outside the auth
+// service, the same way the HTTP API checks its Authorization header.
+type authenticator struct {
+	services *service.Service
+}
+
+func (a *authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if isPublic(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if isPublic(info.FullMethod) {
+		return handler(srv, ss)
+	}
+
+	ctx, err := a.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	values := metadata.ValueFromIncomingContext(ctx, authorizationMetadata)
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "empty authorization metadata")
+	}
+
+	parts := strings.Split(values[0], " ")
+	if len(parts) != 2 {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata")
+	}
+
+	userId, err := a.services.Authorization.ParseToken(parts[1])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return context.WithValue(ctx, userKey{}, userId), nil
+}
+
+func isPublic(method string) bool {
+	return strings.HasPrefix(method, "/"+todov1.AuthService_ServiceDesc.ServiceName+"/")
+}
+
+func getUserId(ctx context.Context) int {
+	return ctx.Value(userKey{}).(int)
+}
+
+// servicesFor returns the services acting on behalf of the call, which
+// audit the changes they make with its x-request-id metadata and the
+// address of the peer.
+func servicesFor(ctx context.Context, services *service.Service) *service.Service {
+	var request todo.RequestInfo
+	if values := metadata.ValueFromIncomingContext(ctx, requestIdMetadata); len(values) > 0 {
+		request.Id = values[0]
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			request.IP = host
+		}
+	}
+
+	return services.WithRequest(request)
+}
+
+// contextStream is a server stream carrying a context other than the one
+// it was opened with.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+type authServer struct {
+	todov1.UnimplementedAuthServiceServer
+
+	services *service.Service
+}
+
+func (s *authServer) SignUp(ctx context.Context, req *todov1.SignUpRequest) (*todov1.SignUpResponse, error) {
+	var verr todo.ValidationError
+	required(&verr, "name", req.Name)
+	required(&verr, "username", req.Username)
+	required(&verr, "password", req.Password)
+	if err := verr.OrNil(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	id, err := s.services.Authorization.CreateUser(todo.User{
+		Name:     req.Name,
+		Username: req.Username,
+		Password: req.Password,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todov1.SignUpResponse{Id: int64(id)}, nil
+}
+
+func (s *authServer) SignIn(ctx context.Context, req *todov1.SignInRequest) (*todov1.SignInResponse, error) {
+	var verr todo.ValidationError
+	required(&verr, "username", req.Username)
+	required(&verr, "password", req.Password)
+	if err := verr.OrNil(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	token, err := s.services.Authorization.GenerateToken(req.Username, req.Password)
+	if errors.Is(err, todo.ErrNotFound) {
+		return nil, status.Error(codes.Unauthenticated, "invalid username or password")
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todov1.SignInResponse{Token: token}, nil
+}
+
+func required(verr *todo.ValidationError, field, value string) {
+	if value == "" {
+		verr.Add(field, "is required")
+	}
+}
@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sort"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps a service error onto the status the client is told, the
+// way the HTTP API maps it onto a problem. Invalid fields are listed in a
+// BadRequest detail. Errors that are not recognised are logged and
+// reported without their text.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var verr *todo.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		return validationStatus(verr)
+	case errors.Is(err, todo.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, todo.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, todo.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, todo.ErrPreconditionFailed):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, todo.ErrConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		logrus.Errorf("grpc call failed: %s", err.Error())
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func validationStatus(verr *todo.ValidationError) error {
+	fields := make([]string, 0, len(verr.Fields))
+	for field := range verr.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(fields))
+	for i, field := range fields {
+		violations[i] = &errdetails.BadRequest_FieldViolation{Field: field, Description: verr.Fields[field]}
+	}
+
+	st, err := status.New(codes.InvalidArgument, verr.Error()).WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, verr.Error())
+	}
+
+	return st.Err()
+}
+
+// recoverUnary turns a panicking call into an internal error instead of
+// taking the server down.
+func recoverUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Errorf("grpc call %s panicked: %v\n%s", info.FullMethod, r, debug.Stack())
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func recoverStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Errorf("grpc call %s panicked: %v\n%s", info.FullMethod, r, debug.Stack())
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+
+	return handler(srv, ss)
+}
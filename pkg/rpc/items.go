@@ -0,0 +1,238 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/rpc/todov1"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/service"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type itemServer struct {
+	todov1.UnimplementedItemServiceServer
+
+	services *service.Service
+}
+
+func (s *itemServer) CreateItem(ctx context.Context, req *todov1.CreateItemRequest) (*todov1.Item, error) {
+	var verr todo.ValidationError
+	required(&verr, "item.title", req.GetItem().GetTitle())
+	if err := verr.OrNil(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	item, err := servicesFor(ctx, s.services).TodoItem.Create(getUserId(ctx), int(req.ListId), itemFromProto(req.Item))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return itemToProto(item), nil
+}
+
+func (s *itemServer) ListItems(ctx context.Context, req *todov1.ListItemsRequest) (*todov1.ListItemsResponse, error) {
+	filter := todo.ItemFilter{
+		Done:      req.Done,
+		DueBefore: timeOrNil(req.DueBefore),
+		DueAfter:  timeOrNil(req.DueAfter),
+		Query:     req.Query,
+	}
+	items, more, err := s.services.TodoItem.GetAll(getUserId(ctx), int(req.ListId), filter, todo.Page{
+		Limit:   int(req.Limit),
+		AfterId: int(req.AfterId),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &todov1.ListItemsResponse{Items: make([]*todov1.Item, len(items)), More: more}
+	for i, item := range items {
+		resp.Items[i] = itemToProto(item)
+	}
+
+	return resp, nil
+}
+
+func (s *itemServer) GetItem(ctx context.Context, req *todov1.GetItemRequest) (*todov1.Item, error) {
+	item, err := s.services.TodoItem.GetById(getUserId(ctx), int(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return itemToProto(item), nil
+}
+
+func (s *itemServer) UpdateItem(ctx context.Context, req *todov1.UpdateItemRequest) (*todov1.Item, error) {
+	userId := getUserId(ctx)
+	item := itemFromProto(req.GetItem())
+
+	var err error
+	if paths := req.GetUpdateMask().GetPaths(); len(paths) == 0 {
+		err = servicesFor(ctx, s.services).TodoItem.Replace(userId, int(req.Id), item, int(req.Version))
+	} else {
+		var input todo.UpdateItemInput
+		var verr todo.ValidationError
+		for _, path := range paths {
+			switch path {
+			case "title":
+				input.Title = &item.Title
+			case "description":
+				input.Description = todo.NewNullable(item.Description)
+			case "done":
+				input.Done = &item.Done
+			case "due_date":
+				input.DueDate = todo.NewNullable(item.DueDate)
+			case "priority":
+				input.Priority = &item.Priority
+			case "tags":
+				tags := []string(item.Tags)
+				if tags == nil {
+					tags = []string{}
+				}
+				input.Tags = &tags
+			case "recurrence":
+				input.Recurrence = &item.Recurrence
+			default:
+				verr.Add("update_mask", "unknown field "+path)
+			}
+		}
+		if err := verr.OrNil(); err != nil {
+			return nil, toStatus(err)
+		}
+
+		err = servicesFor(ctx, s.services).TodoItem.Update(userId, int(req.Id), input, int(req.Version))
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return s.GetItem(ctx, &todov1.GetItemRequest{Id: req.Id})
+}
+
+func (s *itemServer) DeleteItem(ctx context.Context, req *todov1.DeleteItemRequest) (*emptypb.Empty, error) {
+	if err := servicesFor(ctx, s.services).TodoItem.Delete(getUserId(ctx), int(req.Id), int(req.Version)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// WatchItems forwards the item events of the list, sending items as they
+// are when the event is handled.
+func (s *itemServer) WatchItems(req *todov1.WatchItemsRequest, stream grpc.ServerStreamingServer[todov1.ItemChange]) error {
+	ctx := stream.Context()
+	userId := getUserId(ctx)
+
+	sub, err := s.services.Events.Subscribe(userId, int(req.ListId))
+	if err != nil {
+		return toStatus(err)
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+
+			change, err := s.itemChange(userId, event)
+			if err != nil {
+				return toStatus(err)
+			}
+			if change == nil {
+				continue
+			}
+			if err := stream.Send(change); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// itemChange turns an event into the change sent to watchers, or nil when
+// there is nothing to tell them.
+func (s *itemServer) itemChange(userId int, event todo.Event) (*todov1.ItemChange, error) {
+	var changeType todov1.ItemChange_Type
+	switch event.Type {
+	case todo.EventItemCreated, todo.EventItemRestored:
+		changeType = todov1.ItemChange_TYPE_CREATED
+	case todo.EventItemUpdated:
+		changeType = todov1.ItemChange_TYPE_UPDATED
+	case todo.EventItemDeleted:
+		return &todov1.ItemChange{
+			Type: todov1.ItemChange_TYPE_DELETED,
+			Item: &todov1.Item{Id: int64(event.ItemId), ListId: int64(event.ListId)},
+		}, nil
+	case todo.EventResync:
+		return &todov1.ItemChange{Type: todov1.ItemChange_TYPE_RESYNC}, nil
+	default:
+		return nil, nil
+	}
+
+	item, err := s.services.TodoItem.GetById(userId, event.ItemId)
+	if errors.Is(err, todo.ErrNotFound) {
+		// changed again since; a later event tells what happened
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &todov1.ItemChange{Type: changeType, Item: itemToProto(item)}, nil
+}
+
+func itemFromProto(item *todov1.Item) todo.TodoItem {
+	if item == nil {
+		item = &todov1.Item{}
+	}
+
+	return todo.TodoItem{
+		Title:       item.Title,
+		Description: item.Description,
+		Done:        item.Done,
+		DueDate:     timeOrNil(item.DueDate),
+		Priority:    int(item.Priority),
+		Tags:        item.Tags,
+		Recurrence:  item.Recurrence,
+	}
+}
+
+func itemToProto(item todo.TodoItem) *todov1.Item {
+	return &todov1.Item{
+		Id:          int64(item.Id),
+		ListId:      int64(item.ListId),
+		Title:       item.Title,
+		Description: item.Description,
+		Done:        item.Done,
+		DueDate:     timestampOrNil(item.DueDate),
+		Priority:    int32(item.Priority),
+		Tags:        item.Tags,
+		Recurrence:  item.Recurrence,
+		Version:     int64(item.Version),
+		CreatedAt:   timestamppb.New(item.CreatedAt),
+	}
+}
+
+func timestampOrNil(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
+
+func timeOrNil(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+
+	t := ts.AsTime()
+	return &t
+}
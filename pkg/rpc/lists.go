@@ -0,0 +1,148 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/rpc/todov1"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/service"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type listServer struct {
+	todov1.UnimplementedListServiceServer
+
+	services *service.Service
+}
+
+func (s *listServer) CreateList(ctx context.Context, req *todov1.CreateListRequest) (*todov1.List, error) {
+	var verr todo.ValidationError
+	required(&verr, "list.title", req.GetList().GetTitle())
+	if err := verr.OrNil(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	list, err := servicesFor(ctx, s.services).TodoList.Create(getUserId(ctx), todo.TodoList{
+		Title:       req.List.Title,
+		Description: req.List.Description,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return listToProto(list), nil
+}
+
+func (s *listServer) ListLists(ctx context.Context, req *todov1.ListListsRequest) (*todov1.ListListsResponse, error) {
+	lists, more, err := s.services.TodoList.GetAll(getUserId(ctx), req.Archived, todo.Page{
+		Limit:   int(req.Limit),
+		AfterId: int(req.AfterId),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &todov1.ListListsResponse{Lists: make([]*todov1.List, len(lists)), More: more}
+	for i, list := range lists {
+		resp.Lists[i] = listToProto(list)
+	}
+
+	return resp, nil
+}
+
+func (s *listServer) GetList(ctx context.Context, req *todov1.GetListRequest) (*todov1.List, error) {
+	list, err := s.services.TodoList.GetById(getUserId(ctx), int(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return listToProto(list), nil
+}
+
+func (s *listServer) UpdateList(ctx context.Context, req *todov1.UpdateListRequest) (*todov1.List, error) {
+	userId := getUserId(ctx)
+	list := req.GetList()
+	if list == nil {
+		list = &todov1.List{}
+	}
+
+	var err error
+	if paths := req.GetUpdateMask().GetPaths(); len(paths) == 0 {
+		err = servicesFor(ctx, s.services).TodoList.Replace(userId, int(req.Id), todo.TodoList{
+			Title:       list.Title,
+			Description: list.Description,
+		}, int(req.Version))
+	} else {
+		var input todo.UpdateListInput
+		var verr todo.ValidationError
+		for _, path := range paths {
+			switch path {
+			case "title":
+				input.Title = &list.Title
+			case "description":
+				input.Description = todo.NewNullable(list.Description)
+			default:
+				verr.Add("update_mask", "unknown field "+path)
+			}
+		}
+		if err := verr.OrNil(); err != nil {
+			return nil, toStatus(err)
+		}
+
+		err = servicesFor(ctx, s.services).TodoList.Update(userId, int(req.Id), input, int(req.Version))
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return s.GetList(ctx, &todov1.GetListRequest{Id: req.Id})
+}
+
+func (s *listServer) DeleteList(ctx context.Context, req *todov1.DeleteListRequest) (*emptypb.Empty, error) {
+	if err := servicesFor(ctx, s.services).TodoList.Delete(getUserId(ctx), int(req.Id), int(req.Version)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *listServer) ArchiveList(ctx context.Context, req *todov1.ArchiveListRequest) (*emptypb.Empty, error) {
+	if err := servicesFor(ctx, s.services).TodoList.Archive(getUserId(ctx), int(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *listServer) UnarchiveList(ctx context.Context, req *todov1.UnarchiveListRequest) (*emptypb.Empty, error) {
+	if err := servicesFor(ctx, s.services).TodoList.Unarchive(getUserId(ctx), int(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *listServer) DuplicateList(ctx context.Context, req *todov1.DuplicateListRequest) (*todov1.DuplicateListResponse, error) {
+	id, err := servicesFor(ctx, s.services).TodoList.Duplicate(getUserId(ctx), int(req.Id), todo.DuplicateListInput{
+		Title:     req.Title,
+		ResetDone: req.ResetDone,
+		ShiftDays: int(req.ShiftDays),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todov1.DuplicateListResponse{Id: int64(id)}, nil
+}
+
+func listToProto(list todo.TodoList) *todov1.List {
+	return &todov1.List{
+		Id:          int64(list.Id),
+		Title:       list.Title,
+		Description: list.Description,
+		ArchivedAt:  timestampOrNil(list.ArchivedAt),
+		Version:     int64(list.Version),
+		CreatedAt:   timestamppb.New(list.CreatedAt),
+	}
+}
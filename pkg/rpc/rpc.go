@@ -0,0 +1,46 @@
+// Package rpc serves the gRPC API over the service layer.
+package rpc
+
+import (
+	"context"
+
+	"github.com/MyNameIsWhaaat/todo-app/pkg/rpc/todov1"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/service"
+	"google.golang.org/grpc"
+)
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=github.com/MyNameIsWhaaat/todo-app --go-grpc_out=../.. --go-grpc_opt=module=github.com/MyNameIsWhaaat/todo-app todo/v1/auth.proto todo/v1/lists.proto todo/v1/items.proto
+
+// NewServer returns a gRPC server with the auth, list and item services
+// registered. Every method but those of the auth service requires a token.
+func NewServer(services *service.Service) *grpc.Server {
+	auth := &authenticator{services: services}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(recoverUnary, auth.unary),
+		grpc.ChainStreamInterceptor(recoverStream, auth.stream),
+	)
+
+	todov1.RegisterAuthServiceServer(server, &authServer{services: services})
+	todov1.RegisterListServiceServer(server, &listServer{services: services})
+	todov1.RegisterItemServiceServer(server, &itemServer{services: services})
+
+	return server
+}
+
+// Shutdown stops the server gracefully, cutting off the calls still running
+// when ctx is done, such as change feeds.
+func Shutdown(ctx context.Context, server *grpc.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		return ctx.Err()
+	}
+}
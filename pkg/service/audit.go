@@ -0,0 +1,94 @@
+package service
+
+import (
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+)
+
+// auditor records changes in the audit log together with the request they
+// were made in.
+type auditor struct {
+	repo    repository.Audit
+	request todo.RequestInfo
+}
+
+// record stores a change to an entity; before or after is nil when the
+// change created or deleted it.
+func (a auditor) record(actorId int, action, entity string, entityId, listId int, before, after interface{}) error {
+	entry := todo.AuditEntry{
+		ActorId:  actorId,
+		Action:   action,
+		Entity:   entity,
+		EntityId: entityId,
+		ListId:   listId,
+	}
+	if a.request.Id != "" {
+		entry.RequestId = &a.request.Id
+	}
+	if a.request.IP != "" {
+		entry.IP = &a.request.IP
+	}
+
+	var err error
+	if entry.Before, entry.After, err = todo.AuditDiff(before, after); err != nil {
+		return err
+	}
+
+	return a.repo.Create(entry)
+}
+
+type AuditService struct {
+	repo     repository.Audit
+	listRepo repository.TodoList
+}
+
+func NewAuditService(repo repository.Audit, listRepo repository.TodoList) *AuditService {
+	return &AuditService{repo: repo, listRepo: listRepo}
+}
+
+// GetListActivity returns a page of the changes made to a list and its
+// items, oldest first, and reports whether more follow. Only members of
+// the list may see them.
+func (s *AuditService) GetListActivity(userId, listId int, page todo.Page) ([]todo.AuditEntry, bool, error) {
+	page, err := page.Normalize()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if _, err := s.listRepo.GetById(userId, listId); err != nil {
+		return nil, false, err
+	}
+
+	entries, err := s.repo.GetByList(listId, todo.Page{Limit: page.Limit + 1, AfterId: page.AfterId})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(entries) > page.Limit {
+		return entries[:page.Limit], true, nil
+	}
+	return entries, false, nil
+}
+
+// Query returns a page of the audit entries matching the filter, oldest
+// first, and reports whether more follow. It is meant for administrators.
+func (s *AuditService) Query(filter todo.AuditFilter, page todo.Page) ([]todo.AuditEntry, bool, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	page, err := page.Normalize()
+	if err != nil {
+		return nil, false, err
+	}
+
+	entries, err := s.repo.Query(filter, todo.Page{Limit: page.Limit + 1, AfterId: page.AfterId})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(entries) > page.Limit {
+		return entries[:page.Limit], true, nil
+	}
+	return entries, false, nil
+}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"crypto/rand"
 	"crypto/sha1"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
@@ -15,6 +17,11 @@ const(
 	salt = "jgdfugh8rr8e9090"
 	signingkey = "sdjfsidufjsidfuksjflskdfj"
 	tokenTTL = 12 * time.Hour
+
+	// EventTicketTTL is how long an event ticket can be used to open a
+	// stream; it only has to outlast connecting.
+	EventTicketTTL = time.Minute
+	eventTicketAudience = "list-events"
 )
 
 type tokenClaims struct{
@@ -22,6 +29,12 @@ type tokenClaims struct{
 	UserId int `json:"user_id"`
 }
 
+// eventTicketClaims are those of a ticket to the event stream of one list.
+type eventTicketClaims struct{
+	tokenClaims
+	ListId int `json:"list_id"`
+}
+
 type AuthService struct {
 	repo repository.Authorization
 }
@@ -53,13 +66,7 @@ func (s *AuthService) GenerateToken(username, password string) (string, error){
 }
 
 func (s *AuthService) ParseToken(accessToken string) (int, error){
-	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-
-		return []byte(signingkey), nil
-	})
+	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, keyFunc)
 	if err != nil{
 		return 0, err
 	}
@@ -68,10 +75,85 @@ func (s *AuthService) ParseToken(accessToken string) (int, error){
 	if !ok{
 		return 0, errors.New("token claims are not of type *tokenClaims")
 	}
+	// tokens with an audience are tickets, good for nothing else
+	if claims.Audience != ""{
+		return 0, errors.New("token is not an access token")
+	}
 
 	return claims.UserId, nil
 }
 
+// GenerateEventTicket returns a ticket that lets the user open the event
+// stream of the list for a short while. Browsers cannot send the access
+// token with EventSource and WebSocket requests, so they pass the ticket in
+// the query instead, where it may end up in logs.
+func (s *AuthService) GenerateEventTicket(userId, listId int) (string, error){
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil{
+		return "", err
+	}
+	id := hex.EncodeToString(buf)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &eventTicketClaims{
+		tokenClaims{
+			jwt.StandardClaims{
+				Audience: eventTicketAudience,
+				Id: id,
+				ExpiresAt: time.Now().Add(EventTicketTTL).Unix(),
+				IssuedAt: time.Now().Unix(),
+			},
+			userId,
+		},
+		listId,
+	})
+
+	return token.SignedString([]byte(signingkey))
+}
+
+// ParseEventTicket returns the user a ticket to the event stream of the
+// list was issued to. A ticket opens a single stream: once parsed, it is
+// used up.
+func (s *AuthService) ParseEventTicket(ticket string, listId int) (int, error){
+	token, err := jwt.ParseWithClaims(ticket, &eventTicketClaims{}, keyFunc)
+	if err != nil{
+		return 0, err
+	}
+
+	claims, ok := token.Claims.(*eventTicketClaims)
+	if !ok{
+		return 0, errors.New("token claims are not of type *eventTicketClaims")
+	}
+	if claims.Audience != eventTicketAudience || claims.ListId != listId{
+		return 0, errors.New("ticket is not for this list")
+	}
+
+	if claims.Id == ""{
+		return 0, errors.New("ticket has no id")
+	}
+
+	err = s.repo.UseEventTicket(claims.Id, time.Unix(claims.ExpiresAt, 0))
+	if errors.Is(err, todo.ErrConflict){
+		return 0, errors.New("ticket has already been used")
+	}
+	if err != nil{
+		return 0, err
+	}
+
+	return claims.UserId, nil
+}
+
+func keyFunc(token *jwt.Token) (interface{}, error){
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, errors.New("invalid signing method")
+	}
+
+	return []byte(signingkey), nil
+}
+
+func (s *AuthService) IsAdmin(userId int) (bool, error){
+	return s.repo.IsAdmin(userId)
+}
+
 func generatePasswordHash(password string) string{
 	hash :=sha1.New()
 	hash.Write([]byte(password))
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	subscriptionBuffer = 32
+	feedRetryInterval  = time.Second
+	// recentEvents is how many event ids the hub remembers to drop events
+	// the outbox relays more than once.
+	recentEvents = 1024
+)
+
+// EventHub hands the events published by every replica to the subscribers
+// of their lists.
+type EventHub struct {
+	lists repository.TodoList
+
+	mu          sync.Mutex
+	subscribers map[int]map[*Subscription]struct{}
+
+	// seen holds the ids in recent, a ring of the last events received.
+	// Both are only used by Run.
+	seen   map[string]struct{}
+	recent []string
+	next   int
+}
+
+func NewEventHub(lists repository.TodoList) *EventHub {
+	return &EventHub{
+		lists:       lists,
+		subscribers: make(map[int]map[*Subscription]struct{}),
+		seen:        make(map[string]struct{}, recentEvents),
+		recent:      make([]string, recentEvents),
+	}
+}
+
+// Subscription receives the events of one list.
+type Subscription struct {
+	// C delivers the events. It is closed when the subscription ends: on
+	// Close, when the list is deleted, when the subscriber loses access to
+	// it, when the subscriber falls too far behind or when the hub stops.
+	C <-chan todo.Event
+
+	c      chan todo.Event
+	hub    *EventHub
+	userId int
+	listId int
+	once   sync.Once
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.hub.remove(s)
+}
+
+// Subscribe starts delivering the events of the list to a member of it.
+func (h *EventHub) Subscribe(userId, listId int) (*Subscription, error) {
+	if _, err := h.lists.GetById(userId, listId); err != nil {
+		return nil, err
+	}
+
+	c := make(chan todo.Event, subscriptionBuffer)
+	sub := &Subscription{C: c, c: c, hub: h, userId: userId, listId: listId}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers[listId] == nil {
+		h.subscribers[listId] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[listId][sub] = struct{}{}
+
+	return sub, nil
+}
+
+// Run delivers the events received from feed until ctx is cancelled, then
+// ends every subscription.
+func (h *EventHub) Run(ctx context.Context, feed repository.EventFeed) {
+	defer h.closeAll()
+
+	for {
+		event, err := feed.Receive(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logrus.Errorf("failed to receive event: %s", err.Error())
+			time.Sleep(feedRetryInterval)
+			continue
+		}
+
+		if event == nil {
+			h.resync()
+			continue
+		}
+		if h.duplicate(*event) {
+			continue
+		}
+		h.deliver(*event)
+	}
+}
+
+// duplicate reports whether the event has been received before and
+// remembers it otherwise.
+func (h *EventHub) duplicate(event todo.Event) bool {
+	if event.Id == "" {
+		return false
+	}
+	if _, ok := h.seen[event.Id]; ok {
+		return true
+	}
+
+	delete(h.seen, h.recent[h.next])
+	h.recent[h.next] = event.Id
+	h.next = (h.next + 1) % len(h.recent)
+	h.seen[event.Id] = struct{}{}
+
+	return false
+}
+
+// deliver hands the event to the subscribers of its list that still have
+// access to it. Those removed from the list since they subscribed are
+// dropped instead. The subscribers of a deleted list get the event of its
+// deletion and are dropped after it.
+func (h *EventHub) deliver(event todo.Event) {
+	h.mu.Lock()
+	subs := make([]*Subscription, 0, len(h.subscribers[event.ListId]))
+	for sub := range h.subscribers[event.ListId] {
+		subs = append(subs, sub)
+	}
+	h.mu.Unlock()
+
+	allowed := make(map[int]bool)
+	if event.Type != todo.EventListDeleted {
+		for _, sub := range subs {
+			if _, ok := allowed[sub.userId]; !ok {
+				allowed[sub.userId] = h.canAccess(sub.userId, event.ListId)
+			}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, sub := range subs {
+		if _, ok := h.subscribers[event.ListId][sub]; !ok {
+			continue
+		}
+		if event.Type != todo.EventListDeleted && !allowed[sub.userId] {
+			h.remove(sub)
+			continue
+		}
+
+		select {
+		case sub.c <- event:
+		default:
+			logrus.Warnf("dropping subscriber of list %d that fell behind", event.ListId)
+			h.remove(sub)
+		}
+	}
+
+	if event.Type == todo.EventListDeleted {
+		for sub := range h.subscribers[event.ListId] {
+			h.remove(sub)
+		}
+	}
+}
+
+// canAccess reports whether the user still has access to the list. When
+// that cannot be told, access is denied; the subscriber can subscribe again.
+func (h *EventHub) canAccess(userId, listId int) bool {
+	_, err := h.lists.GetById(userId, listId)
+	if err != nil && !errors.Is(err, todo.ErrNotFound) {
+		logrus.Errorf("failed to check access to list %d: %s", listId, err.Error())
+	}
+
+	return err == nil
+}
+
+// resync tells every subscriber that events may have been missed.
+func (h *EventHub) resync() {
+	h.mu.Lock()
+	listIds := make([]int, 0, len(h.subscribers))
+	for listId := range h.subscribers {
+		listIds = append(listIds, listId)
+	}
+	h.mu.Unlock()
+
+	for _, listId := range listIds {
+		h.deliver(todo.NewListEvent(todo.EventResync, 0, listId))
+	}
+}
+
+func (h *EventHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// remove ends a subscription. The caller holds h.mu.
+func (h *EventHub) remove(sub *Subscription) {
+	sub.once.Do(func() {
+		delete(h.subscribers[sub.listId], sub)
+		if len(h.subscribers[sub.listId]) == 0 {
+			delete(h.subscribers, sub.listId)
+		}
+		close(sub.c)
+	})
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+)
+
+// fakeMembersRepo grants access to the lists of its members.
+type fakeMembersRepo struct {
+	repository.TodoList
+
+	mu      sync.Mutex
+	members map[int]bool
+}
+
+func (r *fakeMembersRepo) GetById(userId, listId int) (todo.TodoList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.members[userId] {
+		return todo.TodoList{}, todo.ErrNotFound
+	}
+	return todo.TodoList{Id: listId}, nil
+}
+
+func TestEventHubDropsRemovedMembers(t *testing.T) {
+	lists := &fakeMembersRepo{members: map[int]bool{1: true, 2: true}}
+	hub := NewEventHub(lists)
+
+	member, err := hub.Subscribe(1, 7)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	removed, err := hub.Subscribe(2, 7)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	lists.mu.Lock()
+	delete(lists.members, 2)
+	lists.mu.Unlock()
+
+	event := todo.NewItemEvent(todo.EventItemCreated, 1, 7, 3)
+	hub.deliver(event)
+
+	if got, ok := <-member.C; !ok || got.Id != event.Id {
+		t.Errorf("member got %+v, %v, want the event", got, ok)
+	}
+	if got, ok := <-removed.C; ok {
+		t.Errorf("removed member got %+v, want the subscription closed", got)
+	}
+}
+
+func TestEventHubDeliversListDeletion(t *testing.T) {
+	lists := &fakeMembersRepo{members: map[int]bool{1: true}}
+	hub := NewEventHub(lists)
+
+	sub, err := hub.Subscribe(1, 7)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// a deleted list is no longer found, but its subscribers hear of it
+	lists.mu.Lock()
+	delete(lists.members, 1)
+	lists.mu.Unlock()
+
+	hub.deliver(todo.NewListEvent(todo.EventListDeleted, 1, 7))
+
+	if got, ok := <-sub.C; !ok || got.Type != todo.EventListDeleted {
+		t.Errorf("got %+v, %v, want the deletion", got, ok)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Error("subscription still open after the deletion")
+	}
+}
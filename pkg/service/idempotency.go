@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+	"github.com/sirupsen/logrus"
+)
+
+type IdempotencyService struct {
+	repo repository.Idempotency
+}
+
+func NewIdempotencyService(repo repository.Idempotency) *IdempotencyService {
+	return &IdempotencyService{repo: repo}
+}
+
+// Begin claims the key for a request with the given fingerprint. It returns
+// nil when the request should run, or the stored record when it already
+// completed and should be replayed.
+func (s *IdempotencyService) Begin(userId int, key, fingerprint string) (*todo.IdempotencyRecord, error) {
+	now := time.Now()
+	claimed, err := s.repo.Reserve(userId, key, fingerprint, now.Add(-todo.IdempotencyKeyTTL), now.Add(-todo.IdempotencyKeyLease))
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	record, err := s.repo.Get(userId, key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case record.Fingerprint != fingerprint:
+		return nil, todo.ErrIdempotencyKeyReused
+	case record.Status == 0:
+		return nil, todo.ErrIdempotencyKeyInProgress
+	}
+
+	return &record, nil
+}
+
+// Complete stores the response to replay for the key.
+func (s *IdempotencyService) Complete(userId int, key string, record todo.IdempotencyRecord) error {
+	return s.repo.Complete(userId, key, record)
+}
+
+// Release frees the key of a request that failed so it can be retried.
+func (s *IdempotencyService) Release(userId int, key string) error {
+	return s.repo.Release(userId, key)
+}
+
+// RunPurge removes expired idempotency keys every interval until ctx is
+// cancelled.
+func (s *IdempotencyService) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := s.repo.Purge(time.Now().Add(-todo.IdempotencyKeyTTL))
+			if err != nil {
+				logrus.Errorf("failed to purge idempotency keys: %s", err.Error())
+				continue
+			}
+			if count > 0 {
+				logrus.Infof("purged %d idempotency keys", count)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+)
+
+type ListTemplateService struct {
+	repo     repository.ListTemplate
+	lists    *TodoListService
+	listRepo repository.TodoList
+	itemRepo repository.TodoItem
+}
+
+func NewListTemplateService(repo repository.ListTemplate, lists *TodoListService, listRepo repository.TodoList, itemRepo repository.TodoItem) *ListTemplateService {
+	return &ListTemplateService{
+		repo:     repo,
+		lists:    lists,
+		listRepo: listRepo,
+		itemRepo: itemRepo,
+	}
+}
+
+func (s *ListTemplateService) CreateFromList(userId, listId int, input todo.SaveTemplateInput) (int, error) {
+	list, err := s.listRepo.GetById(userId, listId)
+	if err != nil {
+		return 0, err
+	}
+
+	items, err := s.itemRepo.GetAll(userId, listId, todo.ItemFilter{}, todo.Page{})
+	if err != nil {
+		return 0, err
+	}
+
+	template := todo.ListTemplate{
+		Title:       list.Title,
+		Description: list.Description,
+		Items:       make([]todo.TemplateItem, 0, len(items)),
+	}
+	if input.Title != nil {
+		template.Title = *input.Title
+	}
+
+	for _, item := range items {
+		template.Items = append(template.Items, todo.TemplateItem{
+			Title:       item.Title,
+			Description: item.Description,
+		})
+	}
+
+	return s.repo.Create(userId, template)
+}
+
+func (s *ListTemplateService) GetAll(userId int) ([]todo.ListTemplate, error) {
+	return s.repo.GetAll(userId)
+}
+
+func (s *ListTemplateService) GetById(userId, templateId int) (todo.ListTemplate, error) {
+	return s.repo.GetById(userId, templateId)
+}
+
+func (s *ListTemplateService) Delete(userId, templateId int) error {
+	return s.repo.Delete(userId, templateId)
+}
+
+// Instantiate creates a new list from the template, replacing {{name}}
+// placeholders in item titles with the given variables.
+func (s *ListTemplateService) Instantiate(userId, templateId int, input todo.InstantiateTemplateInput) (int, error) {
+	template, err := s.repo.GetById(userId, templateId)
+	if err != nil {
+		return 0, err
+	}
+
+	pairs := make([]string, 0, len(input.Variables)*2)
+	for name, value := range input.Variables {
+		pairs = append(pairs, "{{"+name+"}}", value)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	list := todo.TodoList{
+		Title:       template.Title,
+		Description: template.Description,
+	}
+	if input.Title != nil {
+		list.Title = *input.Title
+	}
+
+	items := make([]todo.TodoItem, 0, len(template.Items))
+	for _, item := range template.Items {
+		items = append(items, todo.TodoItem{
+			Title:       replacer.Replace(item.Title),
+			Description: item.Description,
+		})
+	}
+
+	return s.lists.createWithItems(userId, list, items)
+}
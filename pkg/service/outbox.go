@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// outboxLease is how long a claimed message is hidden from other
+	// relays; it has to outlast sending it to every sink.
+	outboxLease     = time.Minute
+	outboxBatchSize = 100
+
+	// A message that could not be relayed is retried after
+	// outboxRetryBase, doubling every attempt up to outboxRetryMax. It is
+	// never given up on.
+	outboxRetryBase = time.Second
+	outboxRetryMax  = 5 * time.Minute
+
+	outboxPurgeInterval = time.Hour
+)
+
+// EventSink is a destination the outbox relay sends events to. An event
+// may be sent more than once, so sinks have to drop the ids they have
+// already seen.
+type EventSink interface {
+	Name() string
+	Send(ctx context.Context, event todo.Event) error
+}
+
+// NotifySink sends events to the subscribers of their list on every
+// replica.
+func NotifySink(events repository.Events) EventSink {
+	return notifySink{events: events}
+}
+
+type notifySink struct {
+	events repository.Events
+}
+
+func (s notifySink) Name() string {
+	return "notify"
+}
+
+func (s notifySink) Send(ctx context.Context, event todo.Event) error {
+	return s.events.Publish(event)
+}
+
+// WebhookSink queues events for the webhooks subscribed to them.
+func WebhookSink(webhooks repository.Webhook) EventSink {
+	return webhookSink{webhooks: webhooks}
+}
+
+type webhookSink struct {
+	webhooks repository.Webhook
+}
+
+func (s webhookSink) Name() string {
+	return "webhooks"
+}
+
+func (s webhookSink) Send(ctx context.Context, event todo.Event) error {
+	return s.webhooks.Enqueue(event)
+}
+
+// OutboxRelay sends the events written to the outbox to its sinks. A
+// message is marked published once every sink took it; until then it is
+// retried, so each sink gets every event at least once.
+type OutboxRelay struct {
+	repo  repository.Outbox
+	sinks []EventSink
+}
+
+func NewOutboxRelay(repo repository.Outbox, sinks ...EventSink) *OutboxRelay {
+	return &OutboxRelay{repo: repo, sinks: sinks}
+}
+
+// Relay sends one batch of the messages that are due and returns how many
+// it handled, relayed or not.
+func (r *OutboxRelay) Relay(ctx context.Context) (int, error) {
+	messages, err := r.repo.ClaimDue(outboxBatchSize, outboxLease)
+	if err != nil {
+		return 0, err
+	}
+
+	published := make([]int64, 0, len(messages))
+	for _, message := range messages {
+		if err := r.send(ctx, message); err != nil {
+			logrus.Errorf("failed to relay outbox event %s: %s", message.EventId, err.Error())
+
+			next := time.Now().Add(outboxBackoff(message.Attempts + 1))
+			if err := r.repo.MarkFailed(message.Id, err.Error(), next); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		published = append(published, message.Id)
+	}
+
+	if len(published) > 0 {
+		if err := r.repo.MarkPublished(published); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(messages), nil
+}
+
+// RunRelay relays the outbox every interval and purges the messages
+// published longer than retention ago every hour, until ctx is cancelled.
+func (r *OutboxRelay) RunRelay(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	purge := time.NewTicker(outboxPurgeInterval)
+	defer purge.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Keep going while full batches come back so that a backlog
+			// drains without waiting for the next tick.
+			for ctx.Err() == nil {
+				count, err := r.Relay(ctx)
+				if err != nil {
+					logrus.Errorf("failed to relay outbox: %s", err.Error())
+				}
+				if err != nil || count < outboxBatchSize {
+					break
+				}
+			}
+		case <-purge.C:
+			count, err := r.repo.Purge(time.Now().Add(-retention))
+			if err != nil {
+				logrus.Errorf("failed to purge outbox: %s", err.Error())
+				continue
+			}
+			if count > 0 {
+				logrus.Infof("purged %d outbox messages", count)
+			}
+		}
+	}
+}
+
+// send hands the message to every sink. A sink that fails does not keep
+// the others from getting it; they get it again with the retry.
+func (r *OutboxRelay) send(ctx context.Context, message todo.OutboxMessage) error {
+	var event todo.Event
+	if err := json.Unmarshal(message.Payload, &event); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, sink := range r.sinks {
+		if err := sink.Send(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// outboxBackoff returns how long to wait before relaying a message that
+// failed for the n-th time.
+func outboxBackoff(n int) time.Duration {
+	if n > 16 {
+		return outboxRetryMax
+	}
+
+	return min(outboxRetryBase<<(n-1), outboxRetryMax)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/quickadd"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+)
+
+type QuickAddService struct {
+	items        TodoItem
+	listRepo     repository.TodoList
+	settingsRepo repository.Settings
+	now          func() time.Time
+}
+
+func NewQuickAddService(items TodoItem, listRepo repository.TodoList, settingsRepo repository.Settings) *QuickAddService {
+	return &QuickAddService{
+		items:        items,
+		listRepo:     listRepo,
+		settingsRepo: settingsRepo,
+		now:          time.Now,
+	}
+}
+
+// Create parses the text in the user's time zone and creates the item it
+// describes, returning the item as understood.
+func (s *QuickAddService) Create(userId int, input todo.QuickAddInput) (todo.TodoItem, error) {
+	settings, err := s.settingsRepo.Get(userId)
+	if err != nil {
+		return todo.TodoItem{}, err
+	}
+
+	result := quickadd.Parse(input.Text, s.now().In(settings.Location()))
+
+	var verr todo.ValidationError
+	if strings.TrimSpace(result.Title) == "" {
+		verr.Add("text", "must contain a title")
+	}
+
+	var listId int
+	switch {
+	case result.List != "":
+		listId, err = s.findList(userId, result.List)
+		if err != nil {
+			return todo.TodoItem{}, err
+		}
+		if listId == 0 {
+			verr.Add("text", fmt.Sprintf("names unknown list %q", result.List))
+		}
+	case input.ListId != nil:
+		listId = *input.ListId
+	default:
+		verr.Add("list_id", "is required when the text names no list")
+	}
+
+	if err := verr.OrNil(); err != nil {
+		return todo.TodoItem{}, err
+	}
+
+	item := todo.TodoItem{
+		ListId:     listId,
+		Title:      result.Title,
+		DueDate:    result.DueDate,
+		Priority:   result.Priority,
+		Tags:       todo.NormalizeTags(result.Tags),
+		Recurrence: result.Recurrence,
+	}
+
+	return s.items.Create(userId, listId, item)
+}
+
+// findList looks up an active list of the user by title, ignoring case and
+// treating dashes and underscores as spaces. It returns 0 if none matches.
+func (s *QuickAddService) findList(userId int, name string) (int, error) {
+	lists, err := s.listRepo.GetAll(userId, false, todo.Page{})
+	if err != nil {
+		return 0, err
+	}
+
+	name = strings.NewReplacer("-", " ", "_", " ").Replace(name)
+	for _, list := range lists {
+		if strings.EqualFold(list.Title, name) {
+			return list.Id, nil
+		}
+	}
+
+	return 0, nil
+}
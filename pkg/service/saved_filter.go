@@ -0,0 +1,78 @@
+package service
+
+import (
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+)
+
+type SavedFilterService struct {
+	repo     repository.SavedFilter
+	itemRepo repository.TodoItem
+}
+
+func NewSavedFilterService(repo repository.SavedFilter, itemRepo repository.TodoItem) *SavedFilterService {
+	return &SavedFilterService{
+		repo:     repo,
+		itemRepo: itemRepo,
+	}
+}
+
+func (s *SavedFilterService) Create(userId int, filter todo.SavedFilter) (int, error) {
+	filter.Query.Tags = todo.NormalizeTags(filter.Query.Tags)
+	if err := filter.Validate(); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Create(userId, filter)
+}
+
+func (s *SavedFilterService) GetAll(userId int) ([]todo.SavedFilter, error) {
+	return s.repo.GetAll(userId)
+}
+
+func (s *SavedFilterService) GetById(userId, filterId int) (todo.SavedFilter, error) {
+	return s.repo.GetById(userId, filterId)
+}
+
+func (s *SavedFilterService) Update(userId, filterId int, input todo.UpdateSavedFilterInput) error {
+	if input.Query != nil {
+		input.Query.Tags = todo.NormalizeTags(input.Query.Tags)
+	}
+
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	return s.repo.Update(userId, filterId, input)
+}
+
+func (s *SavedFilterService) Delete(userId, filterId int) error {
+	return s.repo.Delete(userId, filterId)
+}
+
+// GetItems evaluates the saved filter and returns a page of matching items
+// from all of the user's lists, reporting whether more follow.
+func (s *SavedFilterService) GetItems(userId, filterId int, page todo.Page) ([]todo.TodoItem, bool, error) {
+	page, err := page.Normalize()
+	if err != nil {
+		return nil, false, err
+	}
+
+	filter, err := s.repo.GetById(userId, filterId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	query := filter.Query.Resolve(time.Now())
+	items, err := s.itemRepo.GetByFilter(userId, query, todo.Page{Limit: page.Limit + 1, AfterId: page.AfterId})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(items) > page.Limit {
+		return items[:page.Limit], true, nil
+	}
+	return items, false, nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+)
+
+type SearchService struct {
+	repo repository.Search
+}
+
+func NewSearchService(repo repository.Search) *SearchService {
+	return &SearchService{repo: repo}
+}
+
+func (s *SearchService) Search(userId int, query string, page todo.Page) ([]todo.SearchResult, error) {
+	var verr todo.ValidationError
+	if strings.TrimSpace(query) == "" {
+		verr.Add("q", "must not be empty")
+	}
+	if err := verr.OrNil(); err != nil {
+		return nil, err
+	}
+
+	page, err := page.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.Search(userId, query, page.Limit)
+}
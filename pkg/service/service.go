@@ -1,6 +1,10 @@
 package service
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"github.com/MyNameIsWhaaat/todo-app"
 	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
 )
@@ -9,34 +13,180 @@ type Authorization interface {
 	CreateUser(user todo.User) (int, error)
 	GenerateToken(username, password string) (string, error)
 	ParseToken(token string) (int, error)
+	GenerateEventTicket(userId, listId int) (string, error)
+	ParseEventTicket(ticket string, listId int) (int, error)
+	IsAdmin(userId int) (bool, error)
 }
 
 type TodoList interface {
-	Create(userId int, list todo.TodoList) (int, error)
-	GetAll(userId int) ([]todo.TodoList, error)
+	Create(userId int, list todo.TodoList) (todo.TodoList, error)
+	GetAll(userId int, archived bool, page todo.Page) ([]todo.TodoList, bool, error)
 	GetById(userId, listId int) (todo.TodoList, error)
-	Update(userId, listId int, input todo.UpdateListInput) error
-	Delete(userId, listId int) error
+	GetByIds(userId int, listIds []int) ([]todo.TodoList, error)
+	GetMembers(userId int, listIds []int) ([]todo.ListMember, error)
+	Update(userId, listId int, input todo.UpdateListInput, version int) error
+	Replace(userId, listId int, list todo.TodoList, version int) error
+	Delete(userId, listId int, version int) error
+	Archive(userId, listId int) error
+	Unarchive(userId, listId int) error
+	Duplicate(userId, listId int, input todo.DuplicateListInput) (int, error)
 }
 
 type TodoItem interface {
-	Create(userId int, listId int, item todo.TodoItem) (int, error)
-	GetAll(userId int, listId int) ([]todo.TodoItem, error)
+	Create(userId int, listId int, item todo.TodoItem) (todo.TodoItem, error)
+	GetAll(userId int, listId int, filter todo.ItemFilter, page todo.Page) ([]todo.TodoItem, bool, error)
 	GetById(userId int, itemId int) (todo.TodoItem, error)
-	Update(userId, itemId int, input todo.UpdateItemInput) error
-	Delete(userId, itemId int) error
+	GetByLists(userId int, listIds []int) ([]todo.TodoItem, error)
+	Update(userId, itemId int, input todo.UpdateItemInput, version int) error
+	Replace(userId, itemId int, item todo.TodoItem, version int) error
+	GetRevisions(userId, itemId int, page todo.Page) ([]todo.ItemRevision, bool, error)
+	RestoreRevision(userId, itemId, revision int, version int) error
+	Delete(userId, itemId int, version int) error
+	Bulk(userId int, input todo.BulkItemInput) ([]todo.BulkItemResult, error)
+}
+
+type ListTemplate interface {
+	CreateFromList(userId, listId int, input todo.SaveTemplateInput) (int, error)
+	GetAll(userId int) ([]todo.ListTemplate, error)
+	GetById(userId, templateId int) (todo.ListTemplate, error)
+	Delete(userId, templateId int) error
+	Instantiate(userId, templateId int, input todo.InstantiateTemplateInput) (int, error)
+}
+
+type Trash interface {
+	GetAll(userId int) (todo.Trash, error)
+	RestoreList(userId, listId int) error
+	RestoreItem(userId, itemId int) error
+	Purge(retention time.Duration) (int64, error)
+	RunPurge(ctx context.Context, interval, retention time.Duration)
+}
+
+type Search interface {
+	Search(userId int, query string, page todo.Page) ([]todo.SearchResult, error)
+}
+
+type Settings interface {
+	Get(userId int) (todo.UserSettings, error)
+	Update(userId int, input todo.UpdateSettingsInput) error
+}
+
+type SavedFilter interface {
+	Create(userId int, filter todo.SavedFilter) (int, error)
+	GetAll(userId int) ([]todo.SavedFilter, error)
+	GetById(userId, filterId int) (todo.SavedFilter, error)
+	Update(userId, filterId int, input todo.UpdateSavedFilterInput) error
+	Delete(userId, filterId int) error
+	GetItems(userId, filterId int, page todo.Page) ([]todo.TodoItem, bool, error)
+}
+
+type Views interface {
+	Today(userId int) ([]todo.ListItems, error)
+	Upcoming(userId, days int) ([]todo.ListItems, error)
+	Overdue(userId int) ([]todo.ListItems, error)
+}
+
+type QuickAdd interface {
+	Create(userId int, input todo.QuickAddInput) (todo.TodoItem, error)
+}
+
+type Events interface {
+	Subscribe(userId, listId int) (*Subscription, error)
+	Run(ctx context.Context, feed repository.EventFeed)
+}
+
+type Outbox interface {
+	Relay(ctx context.Context) (int, error)
+	RunRelay(ctx context.Context, interval, retention time.Duration)
+}
+
+type Audit interface {
+	GetListActivity(userId, listId int, page todo.Page) ([]todo.AuditEntry, bool, error)
+	Query(filter todo.AuditFilter, page todo.Page) ([]todo.AuditEntry, bool, error)
+}
+
+type Idempotency interface {
+	Begin(userId int, key, fingerprint string) (*todo.IdempotencyRecord, error)
+	Complete(userId int, key string, record todo.IdempotencyRecord) error
+	Release(userId int, key string) error
+	RunPurge(ctx context.Context, interval time.Duration)
+}
+
+type Webhook interface {
+	Create(userId int, input todo.WebhookInput) (int, error)
+	GetAll(userId int) ([]todo.Webhook, error)
+	GetById(userId, webhookId int) (todo.Webhook, error)
+	Update(userId, webhookId int, input todo.UpdateWebhookInput) error
+	Delete(userId, webhookId int) error
+	GetDeliveries(userId, webhookId int, page todo.Page) ([]todo.WebhookDelivery, bool, error)
+	DeliverDue(ctx context.Context) (int, error)
+	RunDelivery(ctx context.Context, interval time.Duration)
 }
 
 type Service struct {
 	Authorization
 	TodoList
 	TodoItem
+	ListTemplate
+	Trash
+	Search
+	Settings
+	SavedFilter
+	Views
+	QuickAdd
+	Idempotency
+	Webhook
+	Events
+	Outbox
+	Audit
+
+	repos *repository.Repository
+	request todo.RequestInfo
+	// webhookClient is shared by all copies so that they reuse its
+	// connections.
+	webhookClient *http.Client
 }
 
 func NewService(repos *repository.Repository) *Service {
+	return newService(repos, todo.RequestInfo{}, NewEventHub(repos.TodoList), newWebhookClient())
+}
+
+func newService(repos *repository.Repository, request todo.RequestInfo, events Events, webhookClient *http.Client) *Service {
+	todoList := newTodoListService(repos, request)
+	todoItem := NewTodoItemService(repos, request)
+
 	return &Service{
 		Authorization: NewAuthService(repos.Authorization),
-		TodoList: newTodoListService(repos.TodoList),
-		TodoItem: NewTodoItemService(repos.TodoItem, repos.TodoList),
+		TodoList: todoList,
+		TodoItem: todoItem,
+		ListTemplate: NewListTemplateService(repos.ListTemplate, todoList, repos.TodoList, repos.TodoItem),
+		Trash: NewTrashService(repos, request),
+		Search: NewSearchService(repos.Search),
+		Settings: NewSettingsService(repos.Settings),
+		SavedFilter: NewSavedFilterService(repos.SavedFilter, repos.TodoItem),
+		Views: NewViewsService(repos.TodoItem, repos.TodoList, repos.Settings),
+		QuickAdd: NewQuickAddService(todoItem, repos.TodoList, repos.Settings),
+		Idempotency: NewIdempotencyService(repos.Idempotency),
+		Webhook: NewWebhookService(repos.Webhook, webhookClient),
+		Events: events,
+		Outbox: NewOutboxRelay(repos.Outbox, NotifySink(repos.Events), WebhookSink(repos.Webhook)),
+		Audit: NewAuditService(repos.Audit, repos.TodoList),
+		repos: repos,
+		request: request,
+		webhookClient: webhookClient,
 	}
-}
\ No newline at end of file
+}
+
+// WithRequest returns services acting on behalf of a request; the changes
+// they make are audited with its id and client IP.
+func (s *Service) WithRequest(request todo.RequestInfo) *Service {
+	return newService(s.repos, request, s.Events, s.webhookClient)
+}
+
+// Transaction runs fn with services whose repositories share one database
+// transaction, committing it when fn returns nil. Events published inside
+// it are only relayed once it commits.
+func (s *Service) Transaction(fn func(services *Service) error) error {
+	return s.repos.Transaction(func(repos *repository.Repository) error {
+		return fn(newService(repos, s.request, s.Events, s.webhookClient))
+	})
+}
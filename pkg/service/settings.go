@@ -0,0 +1,26 @@
+package service
+
+import (
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+)
+
+type SettingsService struct {
+	repo repository.Settings
+}
+
+func NewSettingsService(repo repository.Settings) *SettingsService {
+	return &SettingsService{repo: repo}
+}
+
+func (s *SettingsService) Get(userId int) (todo.UserSettings, error) {
+	return s.repo.Get(userId)
+}
+
+func (s *SettingsService) Update(userId int, input todo.UpdateSettingsInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	return s.repo.Update(userId, input)
+}
@@ -8,36 +8,363 @@ import (
 type TodoItemService struct {
 	repo repository.TodoItem
 	listRepo repository.TodoList
+	events repository.Events
+	audit auditor
+	revisions repository.ItemRevision
+	repos *repository.Repository
 }
 
-func NewTodoItemService(repo repository.TodoItem, listRepo repository.TodoList) *TodoItemService {
+func NewTodoItemService(repos *repository.Repository, request todo.RequestInfo) *TodoItemService {
 	return &TodoItemService{
-		repo:     repo,
-		listRepo: listRepo,
+		repo:      repos.TodoItem,
+		listRepo:  repos.TodoList,
+		events:    repos.Outbox,
+		audit:     auditor{repo: repos.Audit, request: request},
+		revisions: repos.ItemRevision,
+		repos:     repos,
 	}
 }
 
-func (s *TodoItemService) Create(userId int, listId int, item todo.TodoItem) (int, error){
-	_, err := s.listRepo.GetById(userId, listId)
+// atomic runs fn with a service whose changes, audit entries and events are
+// committed in one transaction.
+func (s *TodoItemService) atomic(fn func(s *TodoItemService) error) error{
+	return s.repos.Transaction(func(repos *repository.Repository) error{
+		return fn(NewTodoItemService(repos, s.audit.request))
+	})
+}
+
+// changed records a change to an item in the audit log and publishes it.
+func (s *TodoItemService) changed(userId int, action string, listId, itemId int, before, after interface{}) error{
+	if err := s.audit.record(userId, action, todo.AuditEntityItem, itemId, listId, before, after); err != nil{
+		return err
+	}
+
+	return s.events.Publish(todo.NewItemEvent(action, userId, listId, itemId))
+}
+
+// completed publishes that an update marked the item done.
+func (s *TodoItemService) completed(userId int, listId int, before, after todo.TodoItem) error{
+	if before.Done || !after.Done{
+		return nil
+	}
+
+	return s.events.Publish(todo.NewItemEvent(todo.EventItemCompleted, userId, listId, after.Id))
+}
+
+func (s *TodoItemService) Create(userId int, listId int, item todo.TodoItem) (todo.TodoItem, error){
+	item.Tags = todo.NormalizeTags(item.Tags)
+	if err := item.Validate(); err != nil{
+		return todo.TodoItem{}, err
+	}
+
+	list, err := s.listRepo.GetById(userId, listId)
 	if err != nil{
-		return 0, err
+		return todo.TodoItem{}, err
+	}
+	if list.ArchivedAt != nil{
+		return todo.TodoItem{}, todo.ErrListArchived
+	}
+
+	err = s.atomic(func(s *TodoItemService) error{
+		if item, err = s.repo.Create(listId, item); err != nil{
+			return err
+		}
+
+		return s.changed(userId, todo.EventItemCreated, listId, item.Id, nil, item)
+	})
+	if err != nil{
+		return todo.TodoItem{}, err
 	}
 
-	return s.repo.Create(listId, item)
+	return item, nil
 }
 
-func (s *TodoItemService) GetAll(userId int, listId int) ([]todo.TodoItem, error){
-	return s.repo.GetAll(listId, userId)
+// GetAll returns a page of the list's items matching the filter and reports
+// whether more follow.
+func (s *TodoItemService) GetAll(userId int, listId int, filter todo.ItemFilter, page todo.Page) ([]todo.TodoItem, bool, error){
+	if err := filter.Validate(); err != nil{
+		return nil, false, err
+	}
+
+	page, err := page.Normalize()
+	if err != nil{
+		return nil, false, err
+	}
+
+	if _, err := s.listRepo.GetById(userId, listId); err != nil{
+		return nil, false, err
+	}
+
+	items, err := s.repo.GetAll(userId, listId, filter, todo.Page{Limit: page.Limit + 1, AfterId: page.AfterId})
+	if err != nil{
+		return nil, false, err
+	}
+
+	if len(items) > page.Limit{
+		return items[:page.Limit], true, nil
+	}
+	return items, false, nil
 }
 
 func (s *TodoItemService) GetById(userId int, itemId int) (todo.TodoItem, error){
 	return s.repo.GetById(userId, itemId)
 }
 
-func (s *TodoItemService) Update(userId, itemId int, input todo.UpdateItemInput) error{
-	return s.repo.Update(userId, itemId, input)
+// GetByLists returns the items of several lists in one go.
+func (s *TodoItemService) GetByLists(userId int, listIds []int) ([]todo.TodoItem, error){
+	return s.repo.GetByLists(userId, listIds)
+}
+
+func (s *TodoItemService) Update(userId, itemId int, input todo.UpdateItemInput, version int) error{
+	if input.Tags != nil{
+		tags := []string(todo.NormalizeTags(*input.Tags))
+		input.Tags = &tags
+	}
+
+	if err := input.Validate(); err != nil{
+		return err
+	}
+
+	listId, err := s.checkWritable(userId, itemId)
+	if err != nil{
+		return err
+	}
+
+	return s.atomic(func(s *TodoItemService) error{
+		before, err := s.repo.GetForUpdate(userId, itemId)
+		if err != nil{
+			return err
+		}
+
+		if err := s.revisions.Create(todo.NewItemRevision(before, userId)); err != nil{
+			return err
+		}
+
+		if err := s.repo.Update(userId, itemId, input, version); err != nil{
+			return err
+		}
+
+		after, err := s.repo.GetById(userId, itemId)
+		if err != nil{
+			return err
+		}
+
+		if err := s.changed(userId, todo.EventItemUpdated, listId, itemId, before, after); err != nil{
+			return err
+		}
+		return s.completed(userId, listId, before, after)
+	})
+}
+
+// Replace overwrites every writable field of the item with those of item.
+func (s *TodoItemService) Replace(userId, itemId int, item todo.TodoItem, version int) error{
+	tags := []string(item.Tags)
+	if tags == nil{
+		tags = []string{}
+	}
+
+	return s.Update(userId, itemId, todo.UpdateItemInput{
+		Title:       &item.Title,
+		Description: todo.NewNullable(item.Description),
+		Done:        &item.Done,
+		DueDate:     todo.NewNullable(item.DueDate),
+		Priority:    &item.Priority,
+		Tags:        &tags,
+		Recurrence:  &item.Recurrence,
+	}, version)
+}
+
+// GetRevisions returns a page of the item's earlier versions, oldest first,
+// and reports whether more follow.
+func (s *TodoItemService) GetRevisions(userId, itemId int, page todo.Page) ([]todo.ItemRevision, bool, error){
+	page, err := page.Normalize()
+	if err != nil{
+		return nil, false, err
+	}
+
+	if _, err := s.repo.GetById(userId, itemId); err != nil{
+		return nil, false, err
+	}
+
+	revisions, err := s.revisions.GetByItem(itemId, todo.Page{Limit: page.Limit + 1, AfterId: page.AfterId})
+	if err != nil{
+		return nil, false, err
+	}
+
+	if len(revisions) > page.Limit{
+		return revisions[:page.Limit], true, nil
+	}
+	return revisions, false, nil
+}
+
+// RestoreRevision reverts the item to the given revision. The restore is an
+// update like any other, so the version it replaces becomes a revision too.
+func (s *TodoItemService) RestoreRevision(userId, itemId, revision int, version int) error{
+	if _, err := s.repo.GetById(userId, itemId); err != nil{
+		return err
+	}
+
+	itemRevision, err := s.revisions.GetByRevision(itemId, revision)
+	if err != nil{
+		return err
+	}
+
+	return s.Replace(userId, itemId, itemRevision.Item(), version)
+}
+
+func (s *TodoItemService) Delete(userId, itemId int, version int) error{
+	listId, err := s.checkWritable(userId, itemId)
+	if err != nil{
+		return err
+	}
+
+	return s.atomic(func(s *TodoItemService) error{
+		before, err := s.repo.GetForUpdate(userId, itemId)
+		if err != nil{
+			return err
+		}
+
+		if err := s.repo.Delete(userId, itemId, version); err != nil{
+			return err
+		}
+
+		return s.changed(userId, todo.EventItemDeleted, listId, itemId, before, nil)
+	})
+}
+
+// Bulk applies one operation to many items atomically and reports the
+// outcome per item.
+func (s *TodoItemService) Bulk(userId int, input todo.BulkItemInput) ([]todo.BulkItemResult, error){
+	if input.Update != nil && input.Update.Tags != nil{
+		tags := []string(todo.NormalizeTags(*input.Update.Tags))
+		input.Update.Tags = &tags
+	}
+	input.AddTags = todo.NormalizeTags(input.AddTags)
+	input.RemoveTags = todo.NormalizeTags(input.RemoveTags)
+
+	if err := input.Validate(); err != nil{
+		return nil, err
+	}
+
+	listIds, err := s.repo.GetListIds(userId, input.Ids)
+	if err != nil{
+		return nil, err
+	}
+
+	var results []todo.BulkItemResult
+	err = s.atomic(func(s *TodoItemService) error{
+		before, err := s.lockItems(userId, input.Ids)
+		if err != nil{
+			return err
+		}
+
+		if results, err = s.repo.Bulk(userId, input); err != nil{
+			return err
+		}
+
+		if input.Operation == todo.BulkDelete{
+			for _, itemId := range input.Ids{
+				if err := s.changed(userId, todo.EventItemDeleted, listIds[itemId], itemId, before[itemId], nil); err != nil{
+					return err
+				}
+			}
+			return nil
+		}
+
+		for _, itemId := range input.Ids{
+			if err := s.revisions.Create(todo.NewItemRevision(before[itemId], userId)); err != nil{
+				return err
+			}
+		}
+
+		after, err := s.getItems(userId, input.Ids)
+		if err != nil{
+			return err
+		}
+
+		for _, itemId := range input.Ids{
+			if err := s.bulkChanged(userId, input, listIds[itemId], before[itemId], after[itemId]); err != nil{
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil{
+		return nil, err
+	}
+
+	return results, nil
 }
 
-func (s *TodoItemService) Delete(userId, itemId int) error{
-	return s.repo.Delete(userId, itemId)
+// bulkChanged records an item updated, tagged or moved in bulk. A move to
+// another list is published as the item leaving one list and entering the
+// other, so that subscribers of each see it.
+func (s *TodoItemService) bulkChanged(userId int, input todo.BulkItemInput, listId int, before, after todo.TodoItem) error{
+	if input.Operation != todo.BulkMove{
+		if err := s.changed(userId, todo.EventItemUpdated, listId, after.Id, before, after); err != nil{
+			return err
+		}
+		return s.completed(userId, listId, before, after)
+	}
+
+	if listId == after.ListId{
+		return nil
+	}
+	if err := s.audit.record(userId, todo.EventItemUpdated, todo.AuditEntityItem, after.Id, after.ListId, before, after); err != nil{
+		return err
+	}
+	if err := s.events.Publish(todo.NewItemEvent(todo.EventItemDeleted, userId, listId, after.Id)); err != nil{
+		return err
+	}
+	return s.events.Publish(todo.NewItemEvent(todo.EventItemCreated, userId, after.ListId, after.Id))
+}
+
+// getItems returns those of the items the user has access to by id.
+func (s *TodoItemService) getItems(userId int, itemIds []int) (map[int]todo.TodoItem, error){
+	items, err := s.repo.GetByIds(userId, itemIds)
+	if err != nil{
+		return nil, err
+	}
+
+	return itemsById(items), nil
+}
+
+// lockItems is getItems for items about to be changed: their rows stay
+// locked until the transaction ends, so the snapshot is the state the
+// change replaces.
+func (s *TodoItemService) lockItems(userId int, itemIds []int) (map[int]todo.TodoItem, error){
+	items, err := s.repo.GetByIdsForUpdate(userId, itemIds)
+	if err != nil{
+		return nil, err
+	}
+
+	return itemsById(items), nil
+}
+
+func itemsById(items []todo.TodoItem) map[int]todo.TodoItem{
+	found := make(map[int]todo.TodoItem, len(items))
+	for _, item := range items{
+		found[item.Id] = item
+	}
+
+	return found
+}
+
+// checkWritable rejects changes to items that belong to an archived list
+// and returns the list of the item otherwise.
+func (s *TodoItemService) checkWritable(userId, itemId int) (int, error){
+	listId, err := s.repo.GetListId(userId, itemId)
+	if err != nil{
+		return 0, err
+	}
+
+	list, err := s.listRepo.GetById(userId, listId)
+	if err != nil{
+		return 0, err
+	}
+	if list.ArchivedAt != nil{
+		return 0, todo.ErrListArchived
+	}
+
+	return listId, nil
 }
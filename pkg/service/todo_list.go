@@ -1,37 +1,254 @@
 package service
 
 import (
+	"time"
+
 	"github.com/MyNameIsWhaaat/todo-app"
 	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
 )
 
 type TodoListService struct {
 	repo repository.TodoList
+	itemRepo repository.TodoItem
+	events repository.Events
+	audit auditor
+	repos *repository.Repository
 }
 
-func newTodoListService(repo repository.TodoList) *TodoListService{
-	return &TodoListService{repo: repo}
+func newTodoListService(repos *repository.Repository, request todo.RequestInfo) *TodoListService{
+	return &TodoListService{
+		repo:     repos.TodoList,
+		itemRepo: repos.TodoItem,
+		events:   repos.Outbox,
+		audit:    auditor{repo: repos.Audit, request: request},
+		repos:    repos,
+	}
 }
 
-func (s *TodoListService) Create(userId int, list todo.TodoList) (int, error){
-	return s.repo.Create(userId, list)
+// atomic runs fn with a service whose changes, audit entries and events are
+// committed in one transaction.
+func (s *TodoListService) atomic(fn func(s *TodoListService) error) error{
+	return s.repos.Transaction(func(repos *repository.Repository) error{
+		return fn(newTodoListService(repos, s.audit.request))
+	})
 }
 
-func (s *TodoListService) GetAll(userId int) ([]todo.TodoList, error){
-	return s.repo.GetAll(userId)
+// changed records a change to a list in the audit log and publishes it.
+func (s *TodoListService) changed(userId int, action string, listId int, before, after interface{}) error{
+	if err := s.audit.record(userId, action, todo.AuditEntityList, listId, listId, before, after); err != nil{
+		return err
+	}
+
+	return s.events.Publish(todo.NewListEvent(action, userId, listId))
+}
+
+func (s *TodoListService) Create(userId int, list todo.TodoList) (todo.TodoList, error){
+	err := s.atomic(func(s *TodoListService) error{
+		var err error
+		if list, err = s.repo.Create(userId, list); err != nil{
+			return err
+		}
+
+		return s.changed(userId, todo.EventListCreated, list.Id, nil, list)
+	})
+	if err != nil{
+		return todo.TodoList{}, err
+	}
+
+	return list, nil
+}
+
+// GetAll returns a page of the user's lists and reports whether more follow.
+func (s *TodoListService) GetAll(userId int, archived bool, page todo.Page) ([]todo.TodoList, bool, error){
+	page, err := page.Normalize()
+	if err != nil{
+		return nil, false, err
+	}
+
+	lists, err := s.repo.GetAll(userId, archived, todo.Page{Limit: page.Limit + 1, AfterId: page.AfterId})
+	if err != nil{
+		return nil, false, err
+	}
+
+	if len(lists) > page.Limit{
+		return lists[:page.Limit], true, nil
+	}
+	return lists, false, nil
 }
 
 func (s *TodoListService) GetById(userId, listId int) (todo.TodoList, error){
 	return s.repo.GetById(userId, listId)
 }
 
-func (s *TodoListService) Delete(userId, listId int) error{
-	return s.repo.Delete(userId, listId)
+// GetByIds returns those of the lists the user has access to.
+func (s *TodoListService) GetByIds(userId int, listIds []int) ([]todo.TodoList, error){
+	return s.repo.GetByIds(userId, listIds)
+}
+
+func (s *TodoListService) GetMembers(userId int, listIds []int) ([]todo.ListMember, error){
+	return s.repo.GetMembers(userId, listIds)
+}
+
+func (s *TodoListService) Delete(userId, listId int, version int) error{
+	return s.atomic(func(s *TodoListService) error{
+		before, err := s.repo.GetById(userId, listId)
+		if err != nil{
+			return err
+		}
+		if before.ArchivedAt != nil{
+			return todo.ErrListArchived
+		}
+
+		if err := s.repo.Delete(userId, listId, version); err != nil{
+			return err
+		}
+
+		return s.changed(userId, todo.EventListDeleted, listId, before, nil)
+	})
 }
 
-func (s *TodoListService) Update(userId, listId int, input todo.UpdateListInput) error{
+func (s *TodoListService) Update(userId, listId int, input todo.UpdateListInput, version int) error{
 	if err := input.Validate(); err != nil{
 		return err
 	}
-	return s.repo.Update(userId, listId, input)
+
+	return s.atomic(func(s *TodoListService) error{
+		before, err := s.repo.GetById(userId, listId)
+		if err != nil{
+			return err
+		}
+		if before.ArchivedAt != nil{
+			return todo.ErrListArchived
+		}
+
+		if err := s.repo.Update(userId, listId, input, version); err != nil{
+			return err
+		}
+
+		after, err := s.repo.GetById(userId, listId)
+		if err != nil{
+			return err
+		}
+
+		return s.changed(userId, todo.EventListUpdated, listId, before, after)
+	})
+}
+
+// Replace overwrites every writable field of the list with those of list.
+func (s *TodoListService) Replace(userId, listId int, list todo.TodoList, version int) error{
+	return s.Update(userId, listId, todo.UpdateListInput{
+		Title:       &list.Title,
+		Description: todo.NewNullable(list.Description),
+	}, version)
+}
+
+func (s *TodoListService) Archive(userId, listId int) error{
+	return s.atomic(func(s *TodoListService) error{
+		before, err := s.repo.GetById(userId, listId)
+		if err != nil{
+			return err
+		}
+
+		if err := s.repo.Archive(userId, listId); err != nil{
+			return err
+		}
+
+		after, err := s.repo.GetById(userId, listId)
+		if err != nil{
+			return err
+		}
+
+		return s.changed(userId, todo.EventListArchived, listId, before, after)
+	})
+}
+
+func (s *TodoListService) Unarchive(userId, listId int) error{
+	return s.atomic(func(s *TodoListService) error{
+		before, err := s.repo.GetById(userId, listId)
+		if err != nil{
+			return err
+		}
+
+		if err := s.repo.Unarchive(userId, listId); err != nil{
+			return err
+		}
+
+		after, err := s.repo.GetById(userId, listId)
+		if err != nil{
+			return err
+		}
+
+		return s.changed(userId, todo.EventListUnarchived, listId, before, after)
+	})
+}
+
+func (s *TodoListService) Duplicate(userId, listId int, input todo.DuplicateListInput) (int, error){
+	list, err := s.repo.GetById(userId, listId)
+	if err != nil{
+		return 0, err
+	}
+
+	items, err := s.itemRepo.GetAll(userId, listId, todo.ItemFilter{}, todo.Page{})
+	if err != nil{
+		return 0, err
+	}
+
+	list.Title = list.Title + " (copy)"
+	if input.Title != nil{
+		list.Title = *input.Title
+	}
+
+	for i := range items{
+		if input.ResetDone{
+			items[i].Done = false
+		}
+
+		if input.ShiftDays != 0 && items[i].DueDate != nil{
+			dueDate := items[i].DueDate.Add(time.Duration(input.ShiftDays) * 24 * time.Hour)
+			items[i].DueDate = &dueDate
+		}
+	}
+
+	return s.createWithItems(userId, list, items)
+}
+
+// createWithItems creates a list filled with items at once. The list and
+// each of its items are recorded and published as created.
+func (s *TodoListService) createWithItems(userId int, list todo.TodoList, items []todo.TodoItem) (int, error){
+	var id int
+	err := s.atomic(func(s *TodoListService) error{
+		var err error
+		if id, err = s.repo.CreateWithItems(userId, list, items); err != nil{
+			return err
+		}
+
+		created, err := s.repo.GetById(userId, id)
+		if err != nil{
+			return err
+		}
+
+		if err := s.changed(userId, todo.EventListCreated, id, nil, created); err != nil{
+			return err
+		}
+
+		createdItems, err := s.itemRepo.GetByLists(userId, []int{id})
+		if err != nil{
+			return err
+		}
+
+		for _, item := range createdItems{
+			if err := s.audit.record(userId, todo.EventItemCreated, todo.AuditEntityItem, item.Id, id, nil, item); err != nil{
+				return err
+			}
+			if err := s.events.Publish(todo.NewItemEvent(todo.EventItemCreated, userId, id, item.Id)); err != nil{
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil{
+		return 0, err
+	}
+
+	return id, nil
 }
\ No newline at end of file
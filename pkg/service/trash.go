@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+	"github.com/sirupsen/logrus"
+)
+
+type TrashService struct {
+	repo     repository.Trash
+	listRepo repository.TodoList
+	itemRepo repository.TodoItem
+	events   repository.Events
+	audit    auditor
+	repos    *repository.Repository
+}
+
+func NewTrashService(repos *repository.Repository, request todo.RequestInfo) *TrashService {
+	return &TrashService{
+		repo:     repos.Trash,
+		listRepo: repos.TodoList,
+		itemRepo: repos.TodoItem,
+		events:   repos.Outbox,
+		audit:    auditor{repo: repos.Audit, request: request},
+		repos:    repos,
+	}
+}
+
+// atomic runs fn with a service whose changes, audit entries and events are
+// committed in one transaction.
+func (s *TrashService) atomic(fn func(s *TrashService) error) error {
+	return s.repos.Transaction(func(repos *repository.Repository) error {
+		return fn(NewTrashService(repos, s.audit.request))
+	})
+}
+
+func (s *TrashService) GetAll(userId int) (todo.Trash, error) {
+	var trash todo.Trash
+
+	lists, err := s.repo.GetLists(userId)
+	if err != nil {
+		return trash, err
+	}
+
+	items, err := s.repo.GetItems(userId)
+	if err != nil {
+		return trash, err
+	}
+
+	trash.Lists = lists
+	trash.Items = items
+
+	return trash, nil
+}
+
+// RestoreList brings the list back from the trash. It is recorded like a
+// deletion undone: with nothing before and the list after.
+func (s *TrashService) RestoreList(userId, listId int) error {
+	return s.atomic(func(s *TrashService) error {
+		if err := s.repo.RestoreList(userId, listId); err != nil {
+			return err
+		}
+
+		after, err := s.listRepo.GetById(userId, listId)
+		if err != nil {
+			return err
+		}
+
+		if err := s.audit.record(userId, todo.EventListRestored, todo.AuditEntityList, listId, listId, nil, after); err != nil {
+			return err
+		}
+		return s.events.Publish(todo.NewListEvent(todo.EventListRestored, userId, listId))
+	})
+}
+
+func (s *TrashService) RestoreItem(userId, itemId int) error {
+	return s.atomic(func(s *TrashService) error {
+		if err := s.repo.RestoreItem(userId, itemId); err != nil {
+			return err
+		}
+
+		after, err := s.itemRepo.GetById(userId, itemId)
+		if err != nil {
+			return err
+		}
+
+		if err := s.audit.record(userId, todo.EventItemRestored, todo.AuditEntityItem, itemId, after.ListId, nil, after); err != nil {
+			return err
+		}
+		return s.events.Publish(todo.NewItemEvent(todo.EventItemRestored, userId, after.ListId, itemId))
+	})
+}
+
+func (s *TrashService) Purge(retention time.Duration) (int64, error) {
+	return s.repo.Purge(time.Now().Add(-retention))
+}
+
+// RunPurge permanently removes trashed rows older than retention every
+// interval until ctx is cancelled.
+func (s *TrashService) RunPurge(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := s.Purge(retention)
+			if err != nil {
+				logrus.Errorf("failed to purge trash: %s", err.Error())
+				continue
+			}
+			if count > 0 {
+				logrus.Infof("purged %d rows from trash", count)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+)
+
+// ViewsService builds the date-based views across all lists of a user.
+// Day boundaries are computed in the user's configured time zone.
+type ViewsService struct {
+	itemRepo     repository.TodoItem
+	listRepo     repository.TodoList
+	settingsRepo repository.Settings
+	now          func() time.Time
+}
+
+func NewViewsService(itemRepo repository.TodoItem, listRepo repository.TodoList, settingsRepo repository.Settings) *ViewsService {
+	return &ViewsService{
+		itemRepo:     itemRepo,
+		listRepo:     listRepo,
+		settingsRepo: settingsRepo,
+		now:          time.Now,
+	}
+}
+
+// Today returns the open items due today, including those already overdue.
+func (s *ViewsService) Today(userId int) ([]todo.ListItems, error) {
+	_, tomorrow, err := s.today(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.view(userId, nil, &tomorrow)
+}
+
+// Upcoming returns the open items due from tomorrow through the given number
+// of days.
+func (s *ViewsService) Upcoming(userId, days int) ([]todo.ListItems, error) {
+	if days < 1 || days > todo.MaxUpcomingDays {
+		var verr todo.ValidationError
+		verr.Add("days", fmt.Sprintf("must be between 1 and %d", todo.MaxUpcomingDays))
+		return nil, verr.OrNil()
+	}
+
+	_, tomorrow, err := s.today(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	to := tomorrow.AddDate(0, 0, days)
+	return s.view(userId, &tomorrow, &to)
+}
+
+// Overdue returns the open items due before the start of today.
+func (s *ViewsService) Overdue(userId int) ([]todo.ListItems, error) {
+	start, _, err := s.today(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.view(userId, nil, &start)
+}
+
+// today returns the bounds of the current day in the user's time zone.
+func (s *ViewsService) today(userId int) (time.Time, time.Time, error) {
+	settings, err := s.settingsRepo.Get(userId)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	now := s.now().In(settings.Location())
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	return start, start.AddDate(0, 0, 1), nil
+}
+
+// view loads the items due in [from, to) and groups them by list, ordering
+// the lists by their earliest due item.
+func (s *ViewsService) view(userId int, from, to *time.Time) ([]todo.ListItems, error) {
+	items, err := s.itemRepo.GetDueBetween(userId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]todo.ListItems, 0)
+	if len(items) == 0 {
+		return groups, nil
+	}
+
+	index := make(map[int]int)
+	listIds := make([]int, 0)
+	for _, item := range items {
+		i, ok := index[item.ListId]
+		if !ok {
+			i = len(groups)
+			index[item.ListId] = i
+			listIds = append(listIds, item.ListId)
+			groups = append(groups, todo.ListItems{List: todo.TodoList{Id: item.ListId}})
+		}
+		groups[i].Items = append(groups[i].Items, item)
+	}
+
+	lists, err := s.listRepo.GetByIds(userId, listIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		groups[index[list.Id]].List = list
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+)
+
+// fakeDueRepo records the range it was asked for and finds nothing.
+type fakeDueRepo struct {
+	repository.TodoItem
+
+	from, to *time.Time
+}
+
+func (r *fakeDueRepo) GetDueBetween(userId int, from, to *time.Time) ([]todo.TodoItem, error) {
+	r.from, r.to = from, to
+	return nil, nil
+}
+
+type fakeSettingsRepo struct {
+	repository.Settings
+
+	timezone string
+}
+
+func (r fakeSettingsRepo) Get(userId int) (todo.UserSettings, error) {
+	return todo.UserSettings{Timezone: r.timezone}, nil
+}
+
+func TestViewsDayBoundaries(t *testing.T) {
+	// 22:30 UTC is already the next morning in Auckland (UTC+13) and still
+	// the afternoon before in Los Angeles (UTC-7).
+	now := time.Date(2024, time.March, 13, 22, 30, 0, 0, time.UTC)
+	utc := func(day, hour int) *time.Time {
+		t := time.Date(2024, time.March, day, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		timezone string
+		view     func(s *ViewsService) ([]todo.ListItems, error)
+		from, to *time.Time
+	}{
+		{
+			name:     "today ahead of UTC",
+			timezone: "Pacific/Auckland",
+			view:     func(s *ViewsService) ([]todo.ListItems, error) { return s.Today(1) },
+			to:       utc(14, 11),
+		},
+		{
+			name:     "today behind UTC",
+			timezone: "America/Los_Angeles",
+			view:     func(s *ViewsService) ([]todo.ListItems, error) { return s.Today(1) },
+			to:       utc(14, 7),
+		},
+		{
+			name:     "upcoming ahead of UTC",
+			timezone: "Pacific/Auckland",
+			view:     func(s *ViewsService) ([]todo.ListItems, error) { return s.Upcoming(1, 3) },
+			from:     utc(14, 11),
+			to:       utc(17, 11),
+		},
+		{
+			name:     "upcoming behind UTC",
+			timezone: "America/Los_Angeles",
+			view:     func(s *ViewsService) ([]todo.ListItems, error) { return s.Upcoming(1, 3) },
+			from:     utc(14, 7),
+			to:       utc(17, 7),
+		},
+		{
+			name:     "overdue ahead of UTC",
+			timezone: "Pacific/Auckland",
+			view:     func(s *ViewsService) ([]todo.ListItems, error) { return s.Overdue(1) },
+			to:       utc(13, 11),
+		},
+		{
+			name:     "overdue behind UTC",
+			timezone: "America/Los_Angeles",
+			view:     func(s *ViewsService) ([]todo.ListItems, error) { return s.Overdue(1) },
+			to:       utc(13, 7),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := &fakeDueRepo{}
+			s := NewViewsService(items, nil, fakeSettingsRepo{timezone: tt.timezone})
+			s.now = func() time.Time { return now }
+
+			if _, err := tt.view(s); err != nil {
+				t.Fatalf("view error = %v", err)
+			}
+			if !sameTime(items.from, tt.from) || !sameTime(items.to, tt.to) {
+				t.Errorf("range = [%v, %v), want [%v, %v)", items.from, items.to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestViewsUpcomingDays(t *testing.T) {
+	s := NewViewsService(&fakeDueRepo{}, nil, fakeSettingsRepo{timezone: "UTC"})
+
+	for _, days := range []int{0, -1, todo.MaxUpcomingDays + 1} {
+		if _, err := s.Upcoming(1, days); !errors.Is(err, todo.ErrValidation) {
+			t.Errorf("Upcoming(%d) error = %v, want a validation error", days, err)
+		}
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	webhookTimeout = 10 * time.Second
+	// A batch of deliveries is sent by up to webhookWorkers at once.
+	webhookBatchSize = 50
+	webhookWorkers   = 10
+	// webhookLease is how long a claimed delivery is hidden from other
+	// workers. It has to outlast sending the whole batch, with room to
+	// spare for recording the outcomes.
+	webhookLease = 2 * (webhookBatchSize + webhookWorkers - 1) / webhookWorkers * webhookTimeout
+
+	// A delivery is retried after webhookRetryBase, doubling every attempt
+	// up to webhookRetryMax, and given up after webhookMaxAttempts.
+	webhookRetryBase   = 30 * time.Second
+	webhookRetryMax    = 6 * time.Hour
+	webhookMaxAttempts = 8
+
+	// webhookMaxFailures is how many deliveries in a row may fail before
+	// the webhook is disabled.
+	webhookMaxFailures = 20
+
+	maxWebhookErrorLength = 1024
+)
+
+// Headers sent with every delivery.
+const (
+	webhookIdHeader        = "X-Webhook-Id"
+	webhookEventHeader     = "X-Webhook-Event"
+	webhookDeliveryHeader  = "X-Webhook-Delivery"
+	webhookTimestampHeader = "X-Webhook-Timestamp"
+	webhookSignatureHeader = "X-Webhook-Signature"
+)
+
+var errWebhookAddressRefused = errors.New("refusing to connect to a non-public address")
+
+// newWebhookClient returns the client deliveries are sent with. Redirects
+// are not followed: a receiver that moved has to be updated by its owner.
+//
+// It only connects to public addresses, checked on the address it is about
+// to dial, so that a name resolving to an internal address is refused no
+// matter when it started doing so. allowed lists internal ranges to reach
+// anyway. Proxies are not used, since they would dial for it.
+func newWebhookClient(allowed ...netip.Prefix) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+
+			addr := addrPort.Addr().Unmap()
+			if todo.IsPublicAddress(addr) || slices.ContainsFunc(allowed, func(p netip.Prefix) bool { return p.Contains(addr) }) {
+				return nil
+			}
+			return fmt.Errorf("%w: %s", errWebhookAddressRefused, addr)
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+type WebhookService struct {
+	repo   repository.Webhook
+	client *http.Client
+}
+
+func NewWebhookService(repo repository.Webhook, client *http.Client) *WebhookService {
+	return &WebhookService{repo: repo, client: client}
+}
+
+func (s *WebhookService) Create(userId int, input todo.WebhookInput) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Create(userId, input)
+}
+
+func (s *WebhookService) GetAll(userId int) ([]todo.Webhook, error) {
+	return s.repo.GetAll(userId)
+}
+
+func (s *WebhookService) GetById(userId, webhookId int) (todo.Webhook, error) {
+	return s.repo.GetById(userId, webhookId)
+}
+
+func (s *WebhookService) Update(userId, webhookId int, input todo.UpdateWebhookInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	return s.repo.Update(userId, webhookId, input)
+}
+
+func (s *WebhookService) Delete(userId, webhookId int) error {
+	return s.repo.Delete(userId, webhookId)
+}
+
+// GetDeliveries returns a page of the webhook's delivery log, oldest first,
+// and reports whether more follow.
+func (s *WebhookService) GetDeliveries(userId, webhookId int, page todo.Page) ([]todo.WebhookDelivery, bool, error) {
+	page, err := page.Normalize()
+	if err != nil {
+		return nil, false, err
+	}
+
+	deliveries, err := s.repo.GetDeliveries(userId, webhookId, todo.Page{Limit: page.Limit + 1, AfterId: page.AfterId})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(deliveries) > page.Limit {
+		return deliveries[:page.Limit], true, nil
+	}
+	return deliveries, false, nil
+}
+
+// DeliverDue sends one batch of the deliveries that are due and returns
+// how many it claimed. A delivery whose lease ran out while it was being
+// sent belongs to whichever worker claimed it next, which records it.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	dispatches, err := s.repo.ClaimDue(webhookBatchSize, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    []error
+		workers = make(chan struct{}, webhookWorkers)
+		// Deliveries of a webhook disabled part way through were dropped
+		// with it and must not be sent.
+		disabled = make(map[int]bool)
+	)
+	for _, dispatch := range dispatches {
+		workers <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			mu.Lock()
+			skip := disabled[dispatch.WebhookId] || len(errs) > 0
+			mu.Unlock()
+			if skip {
+				return
+			}
+
+			attempt := s.send(ctx, dispatch)
+
+			failures, err := s.repo.RecordAttempt(attempt)
+			if errors.Is(err, todo.ErrPreconditionFailed) {
+				logrus.Warnf("webhook delivery %d was claimed again while being sent", dispatch.Id)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+
+			if failures >= webhookMaxFailures && !disabled[dispatch.WebhookId] {
+				logrus.Warnf("disabling webhook %d after %d failed deliveries", dispatch.WebhookId, failures)
+				if err := s.repo.Disable(dispatch.WebhookId); err != nil {
+					errs = append(errs, err)
+					return
+				}
+				disabled[dispatch.WebhookId] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return 0, err
+	}
+
+	return len(dispatches), nil
+}
+
+// RunDelivery sends the deliveries that are due every interval until ctx
+// is cancelled.
+func (s *WebhookService) RunDelivery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Keep going while full batches come back so that a backlog
+			// drains without waiting for the next tick.
+			for ctx.Err() == nil {
+				count, err := s.DeliverDue(ctx)
+				if err != nil {
+					logrus.Errorf("failed to deliver webhooks: %s", err.Error())
+				}
+				if err != nil || count < webhookBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// send posts the delivery's payload to its webhook, signed with the
+// webhook's secret, and reports the outcome.
+func (s *WebhookService) send(ctx context.Context, dispatch todo.WebhookDispatch) todo.WebhookAttempt {
+	attempt := todo.WebhookAttempt{
+		DeliveryId: dispatch.Id,
+		WebhookId:  dispatch.WebhookId,
+		Status:     todo.DeliverySucceeded,
+	}
+	if dispatch.NextAttemptAt != nil {
+		attempt.Lease = *dispatch.NextAttemptAt
+	}
+
+	status, err := s.post(ctx, dispatch)
+	if status != 0 {
+		attempt.ResponseStatus = &status
+	}
+	if err == nil {
+		return attempt
+	}
+
+	message := err.Error()
+	if len(message) > maxWebhookErrorLength {
+		message = message[:maxWebhookErrorLength]
+	}
+	attempt.Error = &message
+
+	if dispatch.Attempts+1 >= webhookMaxAttempts {
+		attempt.Status = todo.DeliveryFailed
+		return attempt
+	}
+
+	next := time.Now().Add(webhookBackoff(dispatch.Attempts + 1))
+	attempt.Status = todo.DeliveryPending
+	attempt.NextAttemptAt = &next
+
+	return attempt
+}
+
+// post sends the request and returns the response status, failing unless
+// it is 2xx.
+func (s *WebhookService) post(ctx context.Context, dispatch todo.WebhookDispatch) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dispatch.URL, bytes.NewReader(dispatch.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-app-webhooks")
+	req.Header.Set(webhookIdHeader, strconv.Itoa(dispatch.WebhookId))
+	req.Header.Set(webhookEventHeader, dispatch.EventType)
+	req.Header.Set(webhookDeliveryHeader, strconv.FormatInt(dispatch.Id, 10))
+	req.Header.Set(webhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhookSignatureHeader, "sha256="+todo.SignWebhook(dispatch.Secret, timestamp, dispatch.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// webhookBackoff returns how long to wait before retrying a delivery that
+// failed for the n-th time: exponential with up to 10% jitter, so that
+// deliveries failing together do not retry in lockstep.
+func webhookBackoff(n int) time.Duration {
+	delay := webhookRetryMax
+	if n <= 16 {
+		delay = min(webhookRetryBase<<(n-1), webhookRetryMax)
+	}
+
+	return delay + rand.N(delay/10+1)
+}
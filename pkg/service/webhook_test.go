@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MyNameIsWhaaat/todo-app"
+	"github.com/MyNameIsWhaaat/todo-app/pkg/repository"
+)
+
+const testSecret = "0123456789abcdef"
+
+// fakeWebhookRepo keeps the delivery queue of one webhook in memory.
+type fakeWebhookRepo struct {
+	repository.Webhook
+
+	mu         sync.Mutex
+	url        string
+	deliveries []*todo.WebhookDelivery
+	failures   int
+	disabled   bool
+}
+
+func (r *fakeWebhookRepo) ClaimDue(limit int, lease time.Duration) ([]todo.WebhookDispatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var dispatches []todo.WebhookDispatch
+	for _, d := range r.deliveries {
+		if len(dispatches) == limit {
+			break
+		}
+		if d.Status != todo.DeliveryPending || d.NextAttemptAt.After(time.Now()) {
+			continue
+		}
+
+		next := time.Now().Add(lease)
+		d.NextAttemptAt = &next
+		dispatches = append(dispatches, todo.WebhookDispatch{WebhookDelivery: *d, URL: r.url, Secret: testSecret})
+	}
+
+	return dispatches, nil
+}
+
+func (r *fakeWebhookRepo) RecordAttempt(attempt todo.WebhookAttempt) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, d := range r.deliveries {
+		if d.Id != attempt.DeliveryId {
+			continue
+		}
+		if d.Status != todo.DeliveryPending || d.NextAttemptAt == nil || !d.NextAttemptAt.Equal(attempt.Lease) {
+			return 0, todo.ErrPreconditionFailed
+		}
+		d.Status = attempt.Status
+		d.Attempts++
+		d.ResponseStatus = attempt.ResponseStatus
+		d.LastError = attempt.Error
+		d.NextAttemptAt = attempt.NextAttemptAt
+	}
+
+	if attempt.Status == todo.DeliverySucceeded {
+		r.failures = 0
+	} else {
+		r.failures++
+	}
+
+	return r.failures, nil
+}
+
+func (r *fakeWebhookRepo) Disable(webhookId int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.disabled = true
+	for _, d := range r.deliveries {
+		if d.Status == todo.DeliveryPending {
+			d.Status = todo.DeliveryFailed
+			d.NextAttemptAt = nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeWebhookRepo) enqueue(event todo.Event, attempts int) *todo.WebhookDelivery {
+	payload, _ := json.Marshal(event)
+	now := time.Now()
+
+	d := &todo.WebhookDelivery{
+		Id:            int64(len(r.deliveries) + 1),
+		WebhookId:     1,
+		EventType:     event.Type,
+		Payload:       payload,
+		Status:        todo.DeliveryPending,
+		Attempts:      attempts,
+		NextAttemptAt: &now,
+	}
+	r.deliveries = append(r.deliveries, d)
+
+	return d
+}
+
+// receiver is a webhook endpoint that answers with status and records the
+// requests it got. Redirects point to another path of the receiver.
+func receiver(t *testing.T, status int) (*httptest.Server, chan *http.Request, chan []byte) {
+	requests := make(chan *http.Request, 10)
+	bodies := make(chan []byte, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+		if status >= 300 && status < 400 {
+			w.Header().Set("Location", "/moved")
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, requests, bodies
+}
+
+// testWebhookClient is the delivery client, allowed to reach the test
+// receivers on loopback.
+func testWebhookClient() *http.Client {
+	return newWebhookClient(netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128"))
+}
+
+func TestWebhookDeliverDue(t *testing.T) {
+	event := todo.NewItemEvent(todo.EventItemCompleted, 1, 2, 3)
+
+	tests := []struct {
+		name         string
+		status       int
+		attempts     int
+		wantStatus   string
+		wantRetry    bool
+		wantFailures int
+	}{
+		{
+			name:       "accepted",
+			status:     http.StatusNoContent,
+			wantStatus: todo.DeliverySucceeded,
+		},
+		{
+			name:         "rejected is retried",
+			status:       http.StatusInternalServerError,
+			wantStatus:   todo.DeliveryPending,
+			wantRetry:    true,
+			wantFailures: 1,
+		},
+		{
+			name:         "redirect is a failure",
+			status:       http.StatusFound,
+			attempts:     2,
+			wantStatus:   todo.DeliveryPending,
+			wantRetry:    true,
+			wantFailures: 1,
+		},
+		{
+			name:         "last attempt gives up",
+			status:       http.StatusServiceUnavailable,
+			attempts:     webhookMaxAttempts - 1,
+			wantStatus:   todo.DeliveryFailed,
+			wantFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests, bodies := receiver(t, tt.status)
+			repo := &fakeWebhookRepo{url: server.URL}
+			delivery := repo.enqueue(event, tt.attempts)
+			s := NewWebhookService(repo, testWebhookClient())
+
+			before := time.Now()
+			count, err := s.DeliverDue(context.Background())
+			if err != nil {
+				t.Fatalf("DeliverDue() error = %v", err)
+			}
+			if count != 1 {
+				t.Fatalf("DeliverDue() = %d, want 1", count)
+			}
+
+			req, body := <-requests, <-bodies
+			if len(requests) != 0 {
+				t.Errorf("sent %d more requests, want redirects not followed", len(requests))
+			}
+			if got := req.Header.Get(webhookEventHeader); got != event.Type {
+				t.Errorf("%s = %q, want %q", webhookEventHeader, got, event.Type)
+			}
+			if got := req.Header.Get(webhookIdHeader); got != "1" {
+				t.Errorf("%s = %q, want %q", webhookIdHeader, got, "1")
+			}
+			timestamp, err := strconv.ParseInt(req.Header.Get(webhookTimestampHeader), 10, 64)
+			if err != nil {
+				t.Fatalf("invalid %s: %v", webhookTimestampHeader, err)
+			}
+			if got, want := req.Header.Get(webhookSignatureHeader), "sha256="+todo.SignWebhook(testSecret, timestamp, body); got != want {
+				t.Errorf("%s = %q, want %q", webhookSignatureHeader, got, want)
+			}
+			if string(body) != string(delivery.Payload) {
+				t.Errorf("body = %s, want %s", body, delivery.Payload)
+			}
+
+			if delivery.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", delivery.Status, tt.wantStatus)
+			}
+			if delivery.Attempts != tt.attempts+1 {
+				t.Errorf("attempts = %d, want %d", delivery.Attempts, tt.attempts+1)
+			}
+			if delivery.ResponseStatus == nil || *delivery.ResponseStatus != tt.status {
+				t.Errorf("response status = %v, want %d", delivery.ResponseStatus, tt.status)
+			}
+			if tt.wantRetry {
+				earliest := before.Add(webhookRetryBase << tt.attempts)
+				if delivery.NextAttemptAt == nil || delivery.NextAttemptAt.Before(earliest) {
+					t.Errorf("next attempt = %v, want after %v", delivery.NextAttemptAt, earliest)
+				}
+			} else if delivery.NextAttemptAt != nil {
+				t.Errorf("next attempt = %v, want none", delivery.NextAttemptAt)
+			}
+			if repo.failures != tt.wantFailures {
+				t.Errorf("failures = %d, want %d", repo.failures, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestWebhookDeliverDueSkipsScheduled(t *testing.T) {
+	server, requests, _ := receiver(t, http.StatusOK)
+	repo := &fakeWebhookRepo{url: server.URL}
+	later := time.Now().Add(time.Hour)
+	repo.enqueue(todo.NewListEvent(todo.EventListDeleted, 1, 2), 1).NextAttemptAt = &later
+	s := NewWebhookService(repo, testWebhookClient())
+
+	count, err := s.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatalf("DeliverDue() error = %v", err)
+	}
+	if count != 0 || len(requests) != 0 {
+		t.Errorf("DeliverDue() sent %d deliveries that are not due", count)
+	}
+}
+
+func TestWebhookDeliverDueLostLease(t *testing.T) {
+	repo := &fakeWebhookRepo{}
+	delivery := repo.enqueue(todo.NewItemEvent(todo.EventItemCreated, 1, 2, 3), 0)
+
+	// the lease runs out while the receiver is busy and another worker
+	// claims the delivery
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repo.mu.Lock()
+		later := delivery.NextAttemptAt.Add(time.Second)
+		delivery.NextAttemptAt = &later
+		repo.mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+	repo.url = server.URL
+	s := NewWebhookService(repo, testWebhookClient())
+
+	if _, err := s.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("DeliverDue() error = %v", err)
+	}
+	if delivery.Status != todo.DeliveryPending || delivery.Attempts != 0 {
+		t.Errorf("status, attempts = %q, %d, want the attempt left to the new lease holder", delivery.Status, delivery.Attempts)
+	}
+}
+
+func TestWebhookRefusesInternalAddresses(t *testing.T) {
+	server, requests, _ := receiver(t, http.StatusOK)
+	repo := &fakeWebhookRepo{url: server.URL}
+	delivery := repo.enqueue(todo.NewItemEvent(todo.EventItemCreated, 1, 2, 3), 0)
+	s := NewWebhookService(repo, newWebhookClient())
+
+	if _, err := s.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("DeliverDue() error = %v", err)
+	}
+	if len(requests) != 0 {
+		t.Fatal("delivered to a loopback address")
+	}
+	if delivery.Status != todo.DeliveryPending || delivery.LastError == nil || !strings.Contains(*delivery.LastError, errWebhookAddressRefused.Error()) {
+		t.Errorf("status, error = %q, %v, want a refused attempt", delivery.Status, delivery.LastError)
+	}
+}
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "169.254.169.254"},
+		{addr: "100.64.0.1"},
+		{addr: "0.0.0.0"},
+		{addr: "::"},
+		{addr: "fd00::1"},
+		{addr: "fe80::1"},
+		{addr: "::ffff:127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		if got := todo.IsPublicAddress(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("IsPublicAddress(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookDisabledAfterFailures(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		failures     int
+		wantDisabled bool
+	}{
+		{
+			name:         "last allowed failure",
+			status:       http.StatusInternalServerError,
+			failures:     webhookMaxFailures - 1,
+			wantDisabled: true,
+		},
+		{
+			name:     "failures below the limit",
+			status:   http.StatusInternalServerError,
+			failures: webhookMaxFailures - 2,
+		},
+		{
+			name:     "success resets the count",
+			status:   http.StatusOK,
+			failures: webhookMaxFailures - 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _, _ := receiver(t, tt.status)
+			repo := &fakeWebhookRepo{url: server.URL, failures: tt.failures}
+			repo.enqueue(todo.NewItemEvent(todo.EventItemCreated, 1, 2, 3), 0)
+			s := NewWebhookService(repo, testWebhookClient())
+
+			if _, err := s.DeliverDue(context.Background()); err != nil {
+				t.Fatalf("DeliverDue() error = %v", err)
+			}
+			if repo.disabled != tt.wantDisabled {
+				t.Errorf("disabled = %v, want %v", repo.disabled, tt.wantDisabled)
+			}
+		})
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		n    int
+		want time.Duration
+	}{
+		{n: 1, want: 30 * time.Second},
+		{n: 2, want: time.Minute},
+		{n: 5, want: 8 * time.Minute},
+		{n: 12, want: webhookRetryMax},
+		{n: 100, want: webhookRetryMax},
+	}
+
+	for _, tt := range tests {
+		got := webhookBackoff(tt.n)
+		if got < tt.want || got > tt.want+tt.want/10 {
+			t.Errorf("webhookBackoff(%d) = %v, want %v plus up to 10%%", tt.n, got, tt.want)
+		}
+	}
+}
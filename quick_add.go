@@ -0,0 +1,9 @@
+package todo
+
+// QuickAddInput is a single line of text describing an item, for example
+// "Pay rent tomorrow 9am #home !high every month @Bills". ListId is used
+// when the text names no list.
+type QuickAddInput struct {
+	Text   string `json:"text" binding:"required"`
+	ListId *int   `json:"list_id"`
+}
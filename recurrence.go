@@ -0,0 +1,19 @@
+package todo
+
+import "slices"
+
+const (
+	RecurrenceNone    = ""
+	RecurrenceDaily   = "daily"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+	RecurrenceYearly  = "yearly"
+)
+
+var Recurrences = []string{RecurrenceNone, RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly, RecurrenceYearly}
+
+func validateRecurrence(verr *ValidationError, field string, recurrence string) {
+	if !slices.Contains(Recurrences, recurrence) {
+		verr.Add(field, "must be one of daily, weekly, monthly or yearly")
+	}
+}
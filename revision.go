@@ -0,0 +1,50 @@
+package todo
+
+import "time"
+
+// ItemRevision is an item as it was at one version, kept when a change
+// replaced it. Revision is that version; ReplacedBy and ReplacedAt tell who
+// made the change and when.
+type ItemRevision struct {
+	ItemId      int        `json:"item_id" db:"item_id"`
+	Revision    int        `json:"revision" db:"revision"`
+	Title       string     `json:"title" db:"title"`
+	Description *string    `json:"description" db:"description"`
+	Done        bool       `json:"done" db:"done"`
+	DueDate     *time.Time `json:"due_date" db:"due_date"`
+	Priority    int        `json:"priority" db:"priority"`
+	Tags        Tags       `json:"tags" db:"tags"`
+	Recurrence  string     `json:"recurrence" db:"recurrence"`
+	ReplacedBy  int        `json:"replaced_by" db:"replaced_by"`
+	ReplacedAt  time.Time  `json:"replaced_at" db:"replaced_at"`
+}
+
+// NewItemRevision captures the item at its current version.
+func NewItemRevision(item TodoItem, replacedBy int) ItemRevision {
+	return ItemRevision{
+		ItemId:      item.Id,
+		Revision:    item.Version,
+		Title:       item.Title,
+		Description: item.Description,
+		Done:        item.Done,
+		DueDate:     item.DueDate,
+		Priority:    item.Priority,
+		Tags:        item.Tags,
+		Recurrence:  item.Recurrence,
+		ReplacedBy:  replacedBy,
+	}
+}
+
+// Item returns the fields of the revision as an item.
+func (r ItemRevision) Item() TodoItem {
+	return TodoItem{
+		Id:          r.ItemId,
+		Title:       r.Title,
+		Description: r.Description,
+		Done:        r.Done,
+		DueDate:     r.DueDate,
+		Priority:    r.Priority,
+		Tags:        r.Tags,
+		Recurrence:  r.Recurrence,
+	}
+}
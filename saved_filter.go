@@ -0,0 +1,132 @@
+package todo
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	maxFilterTags    = 20
+	maxFilterListIds = 100
+	maxDueWithinDays = 366
+)
+
+// FilterQuery is the definition of a saved filter. All set conditions
+// must hold for an item to match; Tags and ListIds match any of their values.
+type FilterQuery struct {
+	Done          *bool      `json:"done,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+	PriorityMin   *int       `json:"priority_min,omitempty"`
+	PriorityMax   *int       `json:"priority_max,omitempty"`
+	DueBefore     *time.Time `json:"due_before,omitempty"`
+	DueAfter      *time.Time `json:"due_after,omitempty"`
+	DueWithinDays *int       `json:"due_within_days,omitempty"`
+	ListIds       []int      `json:"list_ids,omitempty"`
+}
+
+func (q FilterQuery) Value() (driver.Value, error) {
+	return json.Marshal(q)
+}
+
+func (q *FilterQuery) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, q)
+	case string:
+		return json.Unmarshal([]byte(v), q)
+	default:
+		return fmt.Errorf("cannot scan %T into FilterQuery", src)
+	}
+}
+
+func (q FilterQuery) Validate() error {
+	var verr ValidationError
+	q.validate(&verr)
+
+	return verr.OrNil()
+}
+
+func (q FilterQuery) validate(verr *ValidationError) {
+	if q.PriorityMin != nil {
+		validatePriority(verr, "query.priority_min", *q.PriorityMin)
+	}
+	if q.PriorityMax != nil {
+		validatePriority(verr, "query.priority_max", *q.PriorityMax)
+	}
+	if q.PriorityMin != nil && q.PriorityMax != nil && *q.PriorityMin > *q.PriorityMax {
+		verr.Add("query.priority_min", "must not be greater than priority_max")
+	}
+	if q.DueBefore != nil && q.DueAfter != nil && !q.DueAfter.Before(*q.DueBefore) {
+		verr.Add("query.due_after", "must be earlier than due_before")
+	}
+	if q.DueWithinDays != nil && (*q.DueWithinDays < 0 || *q.DueWithinDays > maxDueWithinDays) {
+		verr.Add("query.due_within_days", "must be between 0 and 366")
+	}
+	if len(q.Tags) > maxFilterTags {
+		verr.Add("query.tags", "must contain at most 20 tags")
+	}
+	validateTags(verr, "query.tags", q.Tags)
+	if len(q.ListIds) > maxFilterListIds {
+		verr.Add("query.list_ids", "must contain at most 100 ids")
+	}
+}
+
+// Resolve turns relative conditions into absolute ones as of now, so the
+// query can be evaluated by the repository.
+func (q FilterQuery) Resolve(now time.Time) FilterQuery {
+	if q.DueWithinDays == nil {
+		return q
+	}
+
+	after := now
+	before := now.AddDate(0, 0, *q.DueWithinDays)
+	if q.DueAfter == nil || q.DueAfter.Before(after) {
+		q.DueAfter = &after
+	}
+	if q.DueBefore == nil || q.DueBefore.After(before) {
+		q.DueBefore = &before
+	}
+	q.DueWithinDays = nil
+
+	return q
+}
+
+type SavedFilter struct {
+	Id    int         `json:"id" db:"id"`
+	Name  string      `json:"name" db:"name" binding:"required"`
+	Query FilterQuery `json:"query" db:"query"`
+}
+
+func (f SavedFilter) Validate() error {
+	var verr ValidationError
+	if strings.TrimSpace(f.Name) == "" {
+		verr.Add("name", "must not be empty")
+	}
+	f.Query.validate(&verr)
+
+	return verr.OrNil()
+}
+
+type UpdateSavedFilterInput struct {
+	Name  *string      `json:"name"`
+	Query *FilterQuery `json:"query"`
+}
+
+func (i UpdateSavedFilterInput) Validate() error {
+	if i.Name == nil && i.Query == nil {
+		return fmt.Errorf("%w: update structure has no values", ErrValidation)
+	}
+
+	var verr ValidationError
+	if i.Name != nil && strings.TrimSpace(*i.Name) == "" {
+		verr.Add("name", "must not be empty")
+	}
+	if i.Query != nil {
+		i.Query.validate(&verr)
+	}
+
+	return verr.OrNil()
+}
@@ -0,0 +1,5 @@
+DROP TABLE template_items;
+
+DROP TABLE list_templates;
+
+ALTER TABLE todo_items DROP COLUMN due_date;
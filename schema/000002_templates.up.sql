@@ -0,0 +1,17 @@
+ALTER TABLE todo_items ADD COLUMN due_date timestamp;
+
+CREATE TABLE list_templates
+(
+id serial not null unique,
+user_id int references users (id) on delete cascade not null,
+title varchar(255) not null,
+description varchar(255)
+);
+
+CREATE TABLE template_items
+(
+id serial not null unique,
+template_id int references list_templates (id) on delete cascade not null,
+title varchar(255) not null,
+description varchar(255)
+);
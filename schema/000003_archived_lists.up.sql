@@ -0,0 +1 @@
+ALTER TABLE todo_lists ADD COLUMN archived_at timestamp;
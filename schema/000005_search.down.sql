@@ -0,0 +1,17 @@
+DROP INDEX todo_items_search_vector_idx;
+
+DROP INDEX todo_lists_search_vector_idx;
+
+DROP TRIGGER todo_items_search_vector_update ON todo_items;
+
+DROP TRIGGER todo_lists_search_vector_update ON todo_lists;
+
+DROP FUNCTION todo_search_vector_update();
+
+ALTER TABLE todo_items DROP COLUMN search_vector;
+ALTER TABLE todo_items DROP COLUMN search_language;
+
+ALTER TABLE todo_lists DROP COLUMN search_vector;
+ALTER TABLE todo_lists DROP COLUMN search_language;
+
+ALTER TABLE users DROP COLUMN search_language;
@@ -0,0 +1,32 @@
+ALTER TABLE users ADD COLUMN search_language regconfig not null default 'english';
+
+ALTER TABLE todo_lists ADD COLUMN search_language regconfig not null default 'english';
+ALTER TABLE todo_lists ADD COLUMN search_vector tsvector;
+
+ALTER TABLE todo_items ADD COLUMN search_language regconfig not null default 'english';
+ALTER TABLE todo_items ADD COLUMN search_vector tsvector;
+
+CREATE FUNCTION todo_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector(NEW.search_language, coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector(NEW.search_language, coalesce(NEW.description, '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todo_lists_search_vector_update
+BEFORE INSERT OR UPDATE OF title, description, search_language ON todo_lists
+FOR EACH ROW EXECUTE FUNCTION todo_search_vector_update();
+
+CREATE TRIGGER todo_items_search_vector_update
+BEFORE INSERT OR UPDATE OF title, description, search_language ON todo_items
+FOR EACH ROW EXECUTE FUNCTION todo_search_vector_update();
+
+UPDATE todo_lists SET search_language = search_language;
+
+UPDATE todo_items SET search_language = search_language;
+
+CREATE INDEX todo_lists_search_vector_idx ON todo_lists USING GIN (search_vector);
+
+CREATE INDEX todo_items_search_vector_idx ON todo_items USING GIN (search_vector);
@@ -0,0 +1,5 @@
+DROP TABLE saved_filters;
+
+DROP TABLE item_tags;
+
+ALTER TABLE todo_items DROP COLUMN priority;
@@ -0,0 +1,18 @@
+ALTER TABLE todo_items ADD COLUMN priority smallint not null default 0 check (priority between 0 and 3);
+
+CREATE TABLE item_tags
+(
+item_id int references todo_items (id) on delete cascade not null,
+tag varchar(64) not null,
+primary key (item_id, tag)
+);
+
+CREATE INDEX item_tags_tag_idx ON item_tags (tag);
+
+CREATE TABLE saved_filters
+(
+id serial not null unique,
+user_id int references users (id) on delete cascade not null,
+name varchar(255) not null,
+query jsonb not null
+);
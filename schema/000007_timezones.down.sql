@@ -0,0 +1,5 @@
+DROP INDEX todo_items_due_date_idx;
+
+ALTER TABLE todo_items ALTER COLUMN due_date TYPE timestamp USING due_date AT TIME ZONE 'UTC';
+
+ALTER TABLE users DROP COLUMN timezone;
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN timezone varchar(64) not null default 'UTC';
+
+ALTER TABLE todo_items ALTER COLUMN due_date TYPE timestamptz USING due_date AT TIME ZONE 'UTC';
+
+CREATE INDEX todo_items_due_date_idx ON todo_items (due_date) WHERE due_date IS NOT NULL AND NOT done;
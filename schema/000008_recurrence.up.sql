@@ -0,0 +1,2 @@
+ALTER TABLE todo_items ADD COLUMN recurrence varchar(16) not null default ''
+    CHECK (recurrence IN ('', 'daily', 'weekly', 'monthly', 'yearly'));
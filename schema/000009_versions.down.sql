@@ -0,0 +1,3 @@
+ALTER TABLE todo_items DROP COLUMN version;
+
+ALTER TABLE todo_lists DROP COLUMN version;
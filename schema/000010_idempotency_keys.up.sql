@@ -0,0 +1,13 @@
+CREATE TABLE idempotency_keys
+(
+user_id int not null,
+key varchar(255) not null,
+fingerprint char(64) not null,
+status smallint not null default 0,
+content_type varchar(255) not null default '',
+body bytea,
+created_at timestamptz not null default now(),
+primary key (user_id, key)
+);
+
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys (created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE idempotency_keys DROP COLUMN location;
+
+ALTER TABLE todo_items DROP COLUMN created_at;
+
+ALTER TABLE todo_lists DROP COLUMN created_at;
@@ -0,0 +1,5 @@
+ALTER TABLE todo_lists ADD COLUMN created_at timestamptz not null default now();
+
+ALTER TABLE todo_items ADD COLUMN created_at timestamptz not null default now();
+
+ALTER TABLE idempotency_keys ADD COLUMN location varchar(255) not null default '';
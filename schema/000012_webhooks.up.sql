@@ -0,0 +1,32 @@
+CREATE TABLE webhooks
+(
+id serial not null unique,
+user_id int references users (id) on delete cascade not null,
+url varchar(2048) not null,
+secret varchar(255) not null,
+events jsonb not null,
+active boolean not null default true,
+failure_count int not null default 0,
+disabled_at timestamptz,
+created_at timestamptz not null default now()
+);
+
+CREATE INDEX webhooks_user_id_idx ON webhooks (user_id);
+
+CREATE TABLE webhook_deliveries
+(
+id bigserial not null unique,
+webhook_id int references webhooks (id) on delete cascade not null,
+event_type varchar(64) not null,
+payload jsonb not null,
+status varchar(16) not null default 'pending',
+attempts int not null default 0,
+response_status int,
+last_error text,
+next_attempt_at timestamptz default now(),
+last_attempt_at timestamptz,
+created_at timestamptz not null default now()
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, id);
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
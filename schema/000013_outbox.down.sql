@@ -0,0 +1,5 @@
+DROP INDEX webhook_deliveries_event_id_idx;
+
+ALTER TABLE webhook_deliveries DROP COLUMN event_id;
+
+DROP TABLE outbox;
@@ -0,0 +1,19 @@
+CREATE TABLE outbox
+(
+id bigserial not null unique,
+event_id uuid not null unique,
+event_type varchar(64) not null,
+payload jsonb not null,
+attempts int not null default 0,
+last_error text,
+next_attempt_at timestamptz not null default now(),
+published_at timestamptz,
+created_at timestamptz not null default now()
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (next_attempt_at) WHERE published_at IS NULL;
+CREATE INDEX outbox_published_at_idx ON outbox (published_at) WHERE published_at IS NOT NULL;
+
+ALTER TABLE webhook_deliveries ADD COLUMN event_id uuid;
+
+CREATE UNIQUE INDEX webhook_deliveries_event_id_idx ON webhook_deliveries (webhook_id, event_id);
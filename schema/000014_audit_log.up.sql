@@ -0,0 +1,20 @@
+ALTER TABLE users ADD COLUMN is_admin boolean not null default false;
+
+CREATE TABLE audit_log
+(
+id bigserial not null unique,
+actor_id int not null,
+action varchar(64) not null,
+entity varchar(16) not null,
+entity_id int not null,
+list_id int not null,
+before jsonb,
+after jsonb,
+request_id text,
+ip text,
+created_at timestamptz not null default now()
+);
+
+CREATE INDEX audit_log_list_id_idx ON audit_log (list_id, id);
+CREATE INDEX audit_log_actor_id_idx ON audit_log (actor_id, id);
+CREATE INDEX audit_log_entity_idx ON audit_log (entity, entity_id, id);
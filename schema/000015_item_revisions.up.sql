@@ -0,0 +1,16 @@
+CREATE TABLE item_revisions
+(
+id bigserial not null unique,
+item_id int references todo_items (id) on delete cascade not null,
+revision int not null,
+title varchar(255) not null,
+description varchar(255),
+done boolean not null,
+due_date timestamptz,
+priority smallint not null,
+tags jsonb not null default '[]',
+recurrence varchar(16) not null,
+replaced_by int not null,
+replaced_at timestamptz not null default now(),
+UNIQUE (item_id, revision)
+);
@@ -0,0 +1,7 @@
+CREATE TABLE used_event_tickets
+(
+id char(32) primary key,
+expires_at timestamptz not null
+);
+
+CREATE INDEX used_event_tickets_expires_at_idx ON used_event_tickets (expires_at);
@@ -0,0 +1,24 @@
+package todo
+
+const (
+	SearchResultList = "list"
+	SearchResultItem = "item"
+)
+
+// SearchLanguages are the Postgres text search configurations users can
+// choose from.
+var SearchLanguages = []string{
+	"simple", "danish", "dutch", "english", "finnish", "french", "german", "hungarian",
+	"italian", "norwegian", "portuguese", "romanian", "russian", "spanish", "swedish", "turkish",
+}
+
+// SearchResult is a list or item matching a search. Its Snippet is HTML:
+// escaped text with the matches wrapped in <mark>.
+type SearchResult struct {
+	Type    string  `json:"type" db:"type"`
+	Id      int     `json:"id" db:"id"`
+	ListId  int     `json:"list_id" db:"list_id"`
+	Title   string  `json:"title" db:"title"`
+	Snippet string  `json:"snippet" db:"snippet"`
+	Rank    float64 `json:"rank" db:"rank"`
+}
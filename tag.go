@@ -0,0 +1,64 @@
+package todo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	PriorityNone = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+const maxTagLength = 64
+
+// Tags is a set of item tags. It scans from a JSON array column.
+type Tags []string
+
+func (t *Tags) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*t = Tags{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return fmt.Errorf("cannot scan %T into Tags", src)
+	}
+}
+
+// NormalizeTags lowercases and trims tags, dropping empty and repeated ones.
+func NormalizeTags(tags []string) Tags {
+	normalized := make(Tags, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(tag, "#")))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+func validateTags(verr *ValidationError, field string, tags []string) {
+	for _, tag := range tags {
+		if len(tag) > maxTagLength {
+			verr.Add(field, "must be at most 64 characters long each")
+			return
+		}
+	}
+}
+
+func validatePriority(verr *ValidationError, field string, priority int) {
+	if priority < PriorityNone || priority > PriorityHigh {
+		verr.Add(field, "must be between 0 and 3")
+	}
+}
@@ -0,0 +1,23 @@
+package todo
+
+type ListTemplate struct {
+	Id          int            `json:"id" db:"id"`
+	Title       string         `json:"title" db:"title"`
+	Description *string        `json:"description" db:"description"`
+	Items       []TemplateItem `json:"items,omitempty"`
+}
+
+type TemplateItem struct {
+	Id          int     `json:"id" db:"id"`
+	Title       string  `json:"title" db:"title"`
+	Description *string `json:"description" db:"description"`
+}
+
+type SaveTemplateInput struct {
+	Title *string `json:"title"`
+}
+
+type InstantiateTemplateInput struct {
+	Title     *string           `json:"title"`
+	Variables map[string]string `json:"variables"`
+}
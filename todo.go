@@ -1,11 +1,19 @@
 package todo
 
-import "errors"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type TodoList struct {
-	Id          int    `json:"id" db:"id"`
-	Title       string `json:"title" db:"title" binding:"required"`
-	Description string `json:"description" db:"description"`
+	Id          int        `json:"id" db:"id"`
+	Title       string     `json:"title" db:"title" binding:"required"`
+	Description *string    `json:"description" db:"description"`
+	ArchivedAt  *time.Time `json:"archived_at" db:"archived_at"`
+	Version     int        `json:"version" db:"version"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
 type UserList struct {
@@ -14,11 +22,36 @@ type UserList struct {
 	ListId int
 }
 
+// ListMember is a user with access to a list.
+type ListMember struct {
+	ListId   int    `json:"list_id" db:"list_id"`
+	Id       int    `json:"id" db:"id"`
+	Name     string `json:"name" db:"name"`
+	Username string `json:"username" db:"username"`
+}
+
 type TodoItem struct {
-	Id          int    `json:"id" db:"id"`
-	Title       string `json:"title" db:"title" binding:"required"`
-	Description string `json:"description" db:"description"`
-	Done        bool   `json:"done" db:"done"`
+	Id          int        `json:"id" db:"id"`
+	ListId      int        `json:"list_id" db:"list_id"`
+	Title       string     `json:"title" db:"title" binding:"required"`
+	Description *string    `json:"description" db:"description"`
+	Done        bool       `json:"done" db:"done"`
+	DueDate     *time.Time `json:"due_date" db:"due_date"`
+	Priority    int        `json:"priority" db:"priority"`
+	Tags        Tags       `json:"tags" db:"tags"`
+	Recurrence  string     `json:"recurrence" db:"recurrence"`
+	Version     int        `json:"version" db:"version"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+func (i TodoItem) Validate() error {
+	var verr ValidationError
+	validatePriority(&verr, "priority", i.Priority)
+	validateTags(&verr, "tags", i.Tags)
+	validateRecurrence(&verr, "recurrence", i.Recurrence)
+
+	return verr.OrNil()
 }
 
 type ListItem struct {
@@ -27,29 +60,62 @@ type ListItem struct {
 	ItemId int
 }
 
+// UpdateListInput is a partial update of a list. Description may be set to
+// null.
 type UpdateListInput struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
+	Title       *string          `json:"title"`
+	Description Nullable[string] `json:"description" swaggertype:"string"`
 }
 
 func (i UpdateListInput) Validate() error {
-	if i.Title == nil && i.Description == nil {
-		return errors.New("update structure has no values")
+	if i.Title == nil && !i.Description.Set {
+		return fmt.Errorf("%w: update structure has no values", ErrValidation)
+	}
+
+	var verr ValidationError
+	if i.Title != nil && strings.TrimSpace(*i.Title) == "" {
+		verr.Add("title", "must not be empty")
 	}
 
-	return nil
+	return verr.OrNil()
 }
 
+type DuplicateListInput struct {
+	Title     *string `json:"title"`
+	ResetDone bool    `json:"reset_done"`
+	ShiftDays int     `json:"shift_days"`
+}
+
+// UpdateItemInput is a partial update of an item. Description and DueDate
+// may be set to null.
 type UpdateItemInput struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	Done        *bool   `json:"done"`
+	Title       *string             `json:"title"`
+	Description Nullable[string]    `json:"description" swaggertype:"string"`
+	Done        *bool               `json:"done"`
+	DueDate     Nullable[time.Time] `json:"due_date" swaggertype:"string" format:"date-time"`
+	Priority    *int                `json:"priority"`
+	Tags        *[]string           `json:"tags"`
+	Recurrence  *string             `json:"recurrence"`
 }
 
 func (i UpdateItemInput) Validate() error {
-	if i.Title == nil && i.Description == nil && i.Done == nil{
-		return errors.New("update structure has no values")
+	if i.Title == nil && !i.Description.Set && i.Done == nil && !i.DueDate.Set && i.Priority == nil && i.Tags == nil && i.Recurrence == nil{
+		return fmt.Errorf("%w: update structure has no values", ErrValidation)
+	}
+
+	var verr ValidationError
+	if i.Title != nil && strings.TrimSpace(*i.Title) == "" {
+		verr.Add("title", "must not be empty")
+	}
+	if i.Priority != nil {
+		validatePriority(&verr, "priority", *i.Priority)
+	}
+	if i.Tags != nil {
+		validateTags(&verr, "tags", *i.Tags)
+	}
+	if i.Recurrence != nil {
+		validateRecurrence(&verr, "recurrence", *i.Recurrence)
 	}
 
-	return nil
+	return verr.OrNil()
 }
\ No newline at end of file
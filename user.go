@@ -1,8 +1,52 @@
 package todo
 
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
 type User struct {
 	Id       int    `json:"-" db:"id"`
 	Name     string `json:"name"     binding:"required"`
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+
+type UserSettings struct {
+	SearchLanguage string `json:"search_language" db:"search_language"`
+	Timezone       string `json:"timezone" db:"timezone"`
+}
+
+// Location returns the user's time zone, falling back to UTC.
+func (s UserSettings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+type UpdateSettingsInput struct {
+	SearchLanguage *string `json:"search_language"`
+	Timezone       *string `json:"timezone"`
+}
+
+func (i UpdateSettingsInput) Validate() error {
+	if i.SearchLanguage == nil && i.Timezone == nil {
+		return fmt.Errorf("%w: update structure has no values", ErrValidation)
+	}
+
+	var verr ValidationError
+	if i.SearchLanguage != nil && !slices.Contains(SearchLanguages, *i.SearchLanguage) {
+		verr.Add("search_language", "is not a supported language")
+	}
+	if i.Timezone != nil {
+		if _, err := time.LoadLocation(*i.Timezone); err != nil || *i.Timezone == "" || *i.Timezone == "Local" {
+			verr.Add("timezone", "is not a known IANA time zone")
+		}
+	}
+
+	return verr.OrNil()
+}
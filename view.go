@@ -0,0 +1,12 @@
+package todo
+
+const (
+	DefaultUpcomingDays = 7
+	MaxUpcomingDays     = 90
+)
+
+// ListItems groups the items of a view under the list they belong to.
+type ListItems struct {
+	List  TodoList   `json:"list"`
+	Items []TodoItem `json:"items"`
+}
@@ -0,0 +1,235 @@
+package todo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxWebhookURLLength    = 2048
+	minWebhookSecretLength = 16
+	maxWebhookSecretLength = 255
+)
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookEvents are the event types a webhook can subscribe to.
+var WebhookEvents = []string{
+	EventListCreated, EventListUpdated, EventListDeleted, EventListArchived, EventListUnarchived, EventListRestored,
+	EventItemCreated, EventItemUpdated, EventItemCompleted, EventItemDeleted, EventItemRestored,
+}
+
+// EventTypes is a set of event types stored as a JSON array.
+type EventTypes []string
+
+func (t EventTypes) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+func (t *EventTypes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return fmt.Errorf("cannot scan %T into EventTypes", src)
+	}
+}
+
+// Webhook posts the events of the lists its owner belongs to to a URL. It
+// is disabled after failing too many deliveries in a row.
+type Webhook struct {
+	Id           int        `json:"id" db:"id"`
+	URL          string     `json:"url" db:"url"`
+	Secret       string     `json:"-" db:"secret"`
+	Events       EventTypes `json:"events" db:"events"`
+	Active       bool       `json:"active" db:"active"`
+	FailureCount int        `json:"failure_count" db:"failure_count"`
+	DisabledAt   *time.Time `json:"disabled_at" db:"disabled_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// WebhookInput registers a webhook. The secret keys the signature of every
+// delivery and is never returned.
+type WebhookInput struct {
+	URL    string     `json:"url" binding:"required"`
+	Secret string     `json:"secret" binding:"required"`
+	Events EventTypes `json:"events" binding:"required"`
+}
+
+func (i WebhookInput) Validate() error {
+	var verr ValidationError
+	validateWebhookURL(&verr, i.URL)
+	validateWebhookSecret(&verr, i.Secret)
+	validateWebhookEvents(&verr, i.Events)
+
+	return verr.OrNil()
+}
+
+// UpdateWebhookInput is a partial update of a webhook. Setting Active to
+// true re-enables a disabled webhook.
+type UpdateWebhookInput struct {
+	URL    *string     `json:"url"`
+	Secret *string     `json:"secret"`
+	Events *EventTypes `json:"events"`
+	Active *bool       `json:"active"`
+}
+
+func (i UpdateWebhookInput) Validate() error {
+	if i.URL == nil && i.Secret == nil && i.Events == nil && i.Active == nil {
+		return fmt.Errorf("%w: update structure has no values", ErrValidation)
+	}
+
+	var verr ValidationError
+	if i.URL != nil {
+		validateWebhookURL(&verr, *i.URL)
+	}
+	if i.Secret != nil {
+		validateWebhookSecret(&verr, *i.Secret)
+	}
+	if i.Events != nil {
+		validateWebhookEvents(&verr, *i.Events)
+	}
+
+	return verr.OrNil()
+}
+
+func validateWebhookURL(verr *ValidationError, rawURL string) {
+	u, err := url.Parse(rawURL)
+	switch {
+	case len(rawURL) > maxWebhookURLLength:
+		verr.Add("url", "must be at most 2048 characters long")
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+		verr.Add("url", "must be an absolute http or https URL")
+	case !publicHost(u.Hostname()):
+		verr.Add("url", "must not point to a local or private address")
+	}
+}
+
+// publicHost rejects the hosts that are known to be internal without
+// resolving them. Names are checked again when deliveries connect.
+func publicHost(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return true
+	}
+
+	return IsPublicAddress(addr)
+}
+
+// nonPublicPrefixes are the ranges besides loopback, private, link-local
+// and unspecified addresses that do not reach the public internet.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// IsPublicAddress reports whether webhooks may be delivered to addr. It
+// keeps users from reaching the network the server runs in.
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateWebhookSecret(verr *ValidationError, secret string) {
+	if len(secret) < minWebhookSecretLength || len(secret) > maxWebhookSecretLength {
+		verr.Add("secret", "must be between 16 and 255 characters long")
+	}
+}
+
+func validateWebhookEvents(verr *ValidationError, events EventTypes) {
+	if len(events) == 0 {
+		verr.Add("events", "must not be empty")
+		return
+	}
+	for _, event := range events {
+		if !slices.Contains(WebhookEvents, event) {
+			verr.Add("events", fmt.Sprintf("%q is not a webhook event", event))
+			return
+		}
+	}
+}
+
+// WebhookDelivery is one event queued for a webhook, with the outcome of
+// its last attempt. NextAttemptAt is nil once it succeeded or failed for
+// good.
+type WebhookDelivery struct {
+	Id             int64           `json:"id" db:"id"`
+	WebhookId      int             `json:"webhook_id" db:"webhook_id"`
+	EventType      string          `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload" swaggertype:"object"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	ResponseStatus *int            `json:"response_status" db:"response_status"`
+	LastError      *string         `json:"last_error" db:"last_error"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at" db:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at" db:"last_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+}
+
+// WebhookDispatch is a delivery that is due together with where it goes.
+type WebhookDispatch struct {
+	WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// WebhookAttempt is the outcome of sending a delivery.
+type WebhookAttempt struct {
+	DeliveryId     int64
+	WebhookId      int
+	Status         string
+	ResponseStatus *int
+	Error          *string
+	// NextAttemptAt is when to try again; nil unless Status is pending.
+	NextAttemptAt *time.Time
+	// Lease is when the claim the delivery was sent under runs out. The
+	// attempt is only recorded while the delivery is still held by it.
+	Lease time.Time
+}
+
+// SignWebhook returns the signature of a webhook payload: the hex encoded
+// HMAC-SHA256 of "<timestamp>.<payload>" keyed with the webhook secret.
+// Receivers recompute it to check the payload came from us unchanged.
+func SignWebhook(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}